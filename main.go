@@ -18,9 +18,14 @@ import (
 	authMiddleware "monolith/auth_service/middleware"
 
 	// Menu (включая корзину и заказы)
+	menuCache "monolith/menu-service/cache"
 	menuDB "monolith/menu-service/database"
+	menuJobs "monolith/menu-service/jobs"
+	menuMedia "monolith/menu-service/media"
 	menuMiddleware "monolith/menu-service/middleware"
 	menuRoutes "monolith/menu-service/routes"
+	menuTransfer "monolith/menu-service/transfer"
+	menuUtils "monolith/menu-service/utils"
 )
 
 func main() {
@@ -28,6 +33,8 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("⚠️ .env файл не найден — используем переменные окружения")
 	}
+	menuUtils.Init()
+	menuCache.Init()
 
 	// === Подключение к базе MENU (через GORM) ===
 	menuDSN := os.Getenv("MENU_DATABASE_URL")
@@ -39,6 +46,9 @@ func main() {
 	}
 	menuDB.Init(db)
 	log.Println("✅ Подключение и миграция базы MENU успешно")
+	menuCache.WatchWrites(db)
+	menuJobs.Start(db)
+	menuMedia.Init()
 
 	// === Подключение к базе AUTH ===
 	authDB.Init()
@@ -46,6 +56,8 @@ func main() {
 
 	// === Инициализация Fiber ===
 	app := fiber.New(fiber.Config{
+		// Самый большой из лимитов загрузки (фото или файл импорта) и запас на multipart-обёртку
+		BodyLimit: int(max(menuMedia.MaxUploadBytes(), menuTransfer.MaxImportBytes)) + 1<<20,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -72,6 +84,11 @@ func main() {
 		return c.SendStatus(fiber.StatusNoContent)
 	})
 
+	// === Загруженные изображения (локальное хранилище) ===
+	if _, ok := menuMedia.Default.(*menuMedia.LocalStorage); ok {
+		app.Static(menuMedia.LocalBaseURL, menuMedia.LocalDir, fiber.Static{MaxAge: 31536000})
+	}
+
 	// === AUTH ===
 	app.Post("/api/register", authHandlers.HandleRegister)
 	app.Post("/api/login", authHandlers.HandleLogin)
@@ -90,10 +107,13 @@ func main() {
 	// === Роуты меню и категории ===
 	menuRoutes.SetupMenuRoutes(app)
 	menuRoutes.SetupCategoryRoutes(app)
+	menuRoutes.SetupLocationRoutes(app)
+	menuRoutes.SetupComboRoutes(app)
 
 	// === Роуты корзины и заказов ===
 	menuRoutes.SetupCartRoutes(app)
 	menuRoutes.SetupOrderRoutes(app)
+	menuRoutes.SetupMeRoutes(app)
 
 	// === Запуск сервера ===
 	port := os.Getenv("PORT")
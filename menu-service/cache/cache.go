@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"monolith/menu-service/config"
+)
+
+// 📦 Закэшированный ответ публичного меню
+type Entry struct {
+	Body            []byte
+	ETag            string
+	ContentType     string
+	ContentLanguage string
+}
+
+// 🗄 Хранилище кэша. Сейчас — память процесса; для нескольких реплик достаточно
+// реализовать этот интерфейс поверх общего хранилища (Redis, Memcached),
+// где Purge рассылает сброс всем репликам.
+type Store interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry, ttl time.Duration)
+	Purge()
+}
+
+// Хранилище, которым пользуются обработчики публичного меню
+var Default Store = NewMemoryStore(1000)
+
+// Время жизни записи: меню зависит и от часов (окна доступности), поэтому даже
+// без записей в БД ответ не может жить дольше TTL (MENU_CACHE_TTL_SECONDS, по умолчанию 60)
+var TTL = 60 * time.Second
+
+// Сколько браузер может не перепроверять ответ (MENU_CACHE_MAX_AGE_SECONDS, по умолчанию 30)
+var MaxAge = 30
+
+// Номер сброса: ответ, собранный до сброса, в кэш уже не кладётся
+var generation atomic.Uint64
+
+// Текущий номер сброса — запоминается перед сборкой ответа и сверяется в SetIfCurrent
+func Generation() uint64 {
+	return generation.Load()
+}
+
+// Сбросить кэш меню
+func Purge() {
+	generation.Add(1)
+	Default.Purge()
+}
+
+// Положить ответ в кэш, если с начала его сборки кэш не сбрасывался: иначе ответ мог
+// прочитать данные до записи, закоммиченной во время запроса, и жил бы устаревшим весь TTL
+func SetIfCurrent(gen uint64, key string, entry Entry) {
+	if generation.Load() == gen {
+		Default.Set(key, entry, TTL)
+	}
+}
+
+// Прочитать настройки кэша из окружения (после загрузки .env)
+func Init() {
+	TTL = time.Duration(config.Int("MENU_CACHE_TTL_SECONDS", 60, 0)) * time.Second
+	MaxAge = config.Int("MENU_CACHE_MAX_AGE_SECONDS", 30, 0)
+}
+
+// 🧠 Кэш в памяти процесса с TTL и ограничением числа записей
+type MemoryStore struct {
+	mu         sync.RWMutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+type memoryEntry struct {
+	Entry
+	expires time.Time
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}, maxEntries: maxEntries}
+}
+
+func (s *MemoryStore) Get(key string) (Entry, bool) {
+	s.mu.RLock()
+	e, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok || time.Now().After(e.expires) {
+		return Entry{}, false
+	}
+	return e.Entry, true
+}
+
+func (s *MemoryStore) Set(key string, entry Entry, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.maxEntries {
+		// ключей немного (адрес + язык + фильтры), поэтому при переполнении
+		// сначала выбрасываем просроченные, а если не помогло — всё
+		now := time.Now()
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		if len(s.entries) >= s.maxEntries {
+			s.entries = map[string]memoryEntry{}
+		}
+	}
+	s.entries[key] = memoryEntry{Entry: entry, expires: time.Now().Add(ttl)}
+}
+
+func (s *MemoryStore) Purge() {
+	s.mu.Lock()
+	s.entries = map[string]memoryEntry{}
+	s.mu.Unlock()
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// Таблицы, изменения в которых меняют публичное меню
+var watchedTables = map[string]bool{
+	"menu_items":              true,
+	"categories":              true,
+	"availability_windows":    true,
+	"stop_list_entries":       true,
+	"menu_item_translations":  true,
+	"category_translations":   true,
+	"menu_calculations":       true, // КБЖУ и аллергены блюда
+	"calculation_ingredients": true,
+	"inventory_items":         true,
+	"locations":               true,
+	"location_menu_items":     true, // цены и скрытие блюд по точкам
+	"location_stocks":         true, // стоп-лист точки зависит от её остатков
+	"combos":                  true,
+	"combo_slots":             true,
+	"combo_slot_items":        true,
+	"menu_item_associations":  true, // рекомендации «заказывают вместе»
+	"reviews":                 true, // рейтинг блюд на витрине
+}
+
+// 🔔 Сбрасывать кэш при любой записи GORM в таблицы меню — так сброс не зависит
+// от того, какой обработчик, импорт или фоновая задача изменили данные.
+// Запись внутри транзакции сбрасывает кэш только после COMMIT: до него параллельный
+// запрос прочитал бы старые данные и снова положил их в кэш. Откаченная транзакция кэш не трогает.
+func WatchWrites(db *gorm.DB) {
+	pool := &watchedPool{ConnPool: db.ConnPool}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+
+	purge := func(tx *gorm.DB) {
+		if tx.Error != nil || !changesMenu(tx.Statement) {
+			return
+		}
+		if t, ok := tx.Statement.ConnPool.(*watchedTx); ok {
+			t.dirty.Store(true)
+			return
+		}
+		Purge()
+	}
+	register := func(name string, err error) {
+		if err != nil {
+			log.Printf("⚠️ Не удалось подключить сброс кэша меню (%s): %v", name, err)
+		}
+	}
+	register("create", db.Callback().Create().After("gorm:create").Register("menu_cache:purge_create", purge))
+	register("update", db.Callback().Update().After("gorm:update").Register("menu_cache:purge_update", purge))
+	register("delete", db.Callback().Delete().After("gorm:delete").Register("menu_cache:purge_delete", purge))
+	register("raw", db.Callback().Raw().After("gorm:raw").Register("menu_cache:purge_raw", purge))
+}
+
+// Меняет ли запрос таблицы меню. У db.Exec таблица не известна — смотрим текст SQL:
+// любой не-SELECT, где упомянута таблица меню (UPDATE, INSERT, DDL триггера поиска)
+func changesMenu(stmt *gorm.Statement) bool {
+	if stmt.Table != "" {
+		return watchedTables[stmt.Table]
+	}
+	sql := strings.ToLower(strings.TrimSpace(stmt.SQL.String()))
+	if sql == "" || strings.HasPrefix(sql, "select") {
+		return false
+	}
+	for table := range watchedTables {
+		if strings.Contains(sql, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// Пул соединений, чьи транзакции запоминают запись в таблицы меню
+type watchedPool struct {
+	gorm.ConnPool
+}
+
+func (p *watchedPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &watchedTx{ConnPool: tx, pool: p}, nil
+}
+
+// Для db.DB(): исходный *sql.DB
+func (p *watchedPool) GetDBConn() (*sql.DB, error) {
+	if db, ok := p.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// Транзакция: сброс кэша откладывается до успешного COMMIT
+type watchedTx struct {
+	gorm.ConnPool
+	pool  *watchedPool
+	dirty atomic.Bool
+}
+
+func (t *watchedTx) Commit() error {
+	if err := t.ConnPool.(gorm.TxCommitter).Commit(); err != nil {
+		return err
+	}
+	if t.dirty.Load() {
+		Purge()
+	}
+	return nil
+}
+
+func (t *watchedTx) Rollback() error {
+	return t.ConnPool.(gorm.TxCommitter).Rollback()
+}
+
+func (t *watchedTx) GetDBConn() (*sql.DB, error) {
+	return t.pool.GetDBConn()
+}
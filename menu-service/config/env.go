@@ -0,0 +1,15 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// 🔧 Целое из переменной окружения; пустое, нечисловое или меньше min значение — fallback.
+// Читать настройки нужно из Init-функций пакетов, уже после загрузки .env.
+func Int(key string, fallback, min int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= min {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// 🧾 Нумерация версий техкарт, созданных до появления версионирования.
+// Уникальный индекс создаётся после заполнения, иначе старые строки с version = 1 его сломают.
+var calculationVersionsSQL = []string{
+	`UPDATE menu_calculations SET effective_from = created_at WHERE effective_from IS NULL`,
+
+	`UPDATE menu_calculations mc
+	SET version = numbered.rn
+	FROM (
+		SELECT id, row_number() OVER (PARTITION BY menu_item_id ORDER BY created_at, id) AS rn
+		FROM menu_calculations
+	) numbered
+	WHERE mc.id = numbered.id
+	  AND NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_menu_calculations_item_version')`,
+
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_calculations_item_version
+		ON menu_calculations (menu_item_id, version)`,
+}
+
+func initCalculationVersions(db *gorm.DB) {
+	for _, stmt := range calculationVersionsSQL {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("⚠️ Не удалось подготовить версии калькуляций: %v", err)
+			return
+		}
+	}
+}
@@ -20,7 +20,32 @@ func Init(db *gorm.DB) {
 		&models.CartItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.ModifierGroup{},
+		&models.ModifierOption{},
+		&models.AvailabilityWindow{},
+		&models.StopListEntry{},
+		&models.MenuItemTranslation{},
+		&models.CategoryTranslation{},
+		&models.InventoryCategoryTranslation{},
+		&models.MediaAsset{},
+		&models.MenuDraft{},
+		&models.MenuSnapshot{},
+		&models.Location{},
+		&models.LocationMenuItem{},
+		&models.LocationStock{},
+		&models.StaffAssignment{},
+		&models.Combo{},
+		&models.ComboSlot{},
+		&models.ComboSlotItem{},
+		&models.MenuItemAssociation{},
+		&models.Review{},
+		&models.Favorite{},
+		&models.PriceChange{},
+		&models.ScheduledPriceChange{},
 	)
+
+	initSearch(DB)
+	initCalculationVersions(DB)
 }
 
 
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// 🔎 SQL для полнотекстового поиска по меню.
+// Документ блюда: название (вес A), категория (вес B), описание (вес C).
+// Вектор пересчитывается триггерами при изменении блюда или названия категории.
+var searchSetupSQL = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+	`ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+
+	`CREATE OR REPLACE FUNCTION menu_item_search_document(p_name text, p_description text, p_category_id uuid)
+	RETURNS tsvector AS $$
+		SELECT
+			setweight(to_tsvector('russian', coalesce(p_name, '')), 'A') ||
+			setweight(to_tsvector('russian', coalesce((SELECT name FROM categories WHERE id = p_category_id), '')), 'B') ||
+			setweight(to_tsvector('russian', coalesce(p_description, '')), 'C')
+	$$ LANGUAGE sql STABLE`,
+
+	`CREATE OR REPLACE FUNCTION menu_items_search_vector_trigger() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector := menu_item_search_document(NEW.name, NEW.description, NEW.category_id);
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS menu_items_search_vector_update ON menu_items`,
+	`CREATE TRIGGER menu_items_search_vector_update
+		BEFORE INSERT OR UPDATE OF name, description, category_id ON menu_items
+		FOR EACH ROW EXECUTE FUNCTION menu_items_search_vector_trigger()`,
+
+	`CREATE OR REPLACE FUNCTION categories_search_vector_trigger() RETURNS trigger AS $$
+	BEGIN
+		UPDATE menu_items
+		SET search_vector = menu_item_search_document(name, description, category_id)
+		WHERE category_id = NEW.id;
+		RETURN NULL;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS categories_search_vector_update ON categories`,
+	`CREATE TRIGGER categories_search_vector_update
+		AFTER UPDATE OF name ON categories
+		FOR EACH ROW EXECUTE FUNCTION categories_search_vector_trigger()`,
+
+	`CREATE INDEX IF NOT EXISTS idx_menu_items_search_vector ON menu_items USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_menu_items_name_trgm ON menu_items USING GIN (lower(name) gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (lower(name) gin_trgm_ops)`,
+
+	// Заполняем вектор для блюд, созданных до появления поиска
+	`UPDATE menu_items
+	SET search_vector = menu_item_search_document(name, description, category_id)
+	WHERE search_vector IS NULL`,
+}
+
+// Подготовка поиска: расширение pg_trgm, колонка, триггеры и индексы
+func initSearch(db *gorm.DB) {
+	for _, stmt := range searchSetupSQL {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("⚠️ Не удалось настроить поиск по меню: %v", err)
+			return
+		}
+	}
+}
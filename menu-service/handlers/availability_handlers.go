@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Расписание, стоп-лист и меню точки на момент запроса
+type orderableView struct {
+	schedule *utils.AvailabilitySchedule
+	stopped  map[string]bool
+	location *utils.LocationMenu
+	now      time.Time
+}
+
+func loadOrderableView(locationID string) (*orderableView, error) {
+	schedule, err := utils.LoadAvailabilitySchedule(database.DB)
+	if err != nil {
+		return nil, err
+	}
+	stopped, err := utils.StoppedMenuItemIDsAt(database.DB, locationID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := utils.LoadLocationMenu(database.DB, locationID)
+	if err != nil {
+		return nil, err
+	}
+	return &orderableView{schedule: schedule, stopped: stopped, location: location, now: utils.RestaurantNow()}, nil
+}
+
+// Блюдо в расписании и продаётся в точке
+func (v *orderableView) visible(id, categoryID string) bool {
+	return v.schedule.Available(id, categoryID, v.now) && !v.location.Hidden(id)
+}
+
+// Оставить только блюда, попадающие в расписание и продающиеся в точке; блюда из стоп-листа
+// остаются в выдаче, но помечаются как недоступные. Цены — по точке, если она указана;
+// для витрины добавляется рейтинг по одобренным отзывам.
+func filterOrderableNow(items []models.MenuItem, locationID string) ([]models.MenuItem, error) {
+	view, err := loadOrderableView(locationID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.MenuItem, 0, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if view.visible(item.ID, item.CategoryID) {
+			item.StopListed = view.stopped[item.ID]
+			item.Price = view.location.Price(item.ID, item.Price)
+			result = append(result, item)
+			ids = append(ids, item.ID)
+		}
+	}
+	ratings, err := utils.RatingSummaries(database.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Rating = ratings[result[i].ID].Average
+		result[i].RatingCount = ratings[result[i].ID].Count
+	}
+	return result, nil
+}
+
+// То же для блюд с категорией
+func filterOrderableNowWithCategory(items []models.MenuItemWithCategory, locationID string) ([]models.MenuItemWithCategory, error) {
+	view, err := loadOrderableView(locationID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.MenuItemWithCategory, 0, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if view.visible(item.ID, item.CategoryID) {
+			item.StopListed = view.stopped[item.ID]
+			item.Price = view.location.Price(item.ID, item.Price)
+			result = append(result, item)
+			ids = append(ids, item.ID)
+		}
+	}
+	ratings, err := utils.RatingSummaries(database.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Rating = ratings[result[i].ID].Average
+		result[i].RatingCount = ratings[result[i].ID].Count
+	}
+	return result, nil
+}
+
+// 🕗 Окна доступности блюда
+func GetMenuItemAvailability(c *fiber.Ctx) error {
+	var windows []models.AvailabilityWindow
+	if err := database.DB.Where("menu_item_id = ?", c.Params("id")).Find(&windows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить расписание",
+		})
+	}
+	return c.JSON(windows)
+}
+
+// ➕ Добавить окно доступности блюду
+func CreateMenuItemAvailability(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+	return createAvailabilityWindow(c, &id, nil)
+}
+
+// 🕗 Окна доступности категории
+func GetCategoryAvailability(c *fiber.Ctx) error {
+	var windows []models.AvailabilityWindow
+	if err := database.DB.Where("category_id = ?", c.Params("id")).Find(&windows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить расписание",
+		})
+	}
+	return c.JSON(windows)
+}
+
+// ➕ Добавить окно доступности категории
+func CreateCategoryAvailability(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var category models.Category
+	if err := database.DB.First(&category, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Категория не найдена",
+		})
+	}
+	return createAvailabilityWindow(c, nil, &id)
+}
+
+func createAvailabilityWindow(c *fiber.Ctx, menuItemID, categoryID *string) error {
+	var window models.AvailabilityWindow
+	if err := c.BodyParser(&window); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ValidateAvailabilityWindow(&window); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	window.ID = ""
+	window.MenuItemID = menuItemID
+	window.CategoryID = categoryID
+	if err := database.DB.Create(&window).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить расписание",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(window)
+}
+
+// ❌ Удалить окно доступности
+func DeleteAvailabilityWindow(c *fiber.Ctx) error {
+	if err := database.DB.Delete(&models.AvailabilityWindow{}, "id = ?", c.Params("windowId")).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить расписание",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Расписание удалено"})
+}
+
+// Тело запроса плановой публикации. Время — RFC 3339 ("2026-06-01T08:00:00+03:00")
+// или без пояса ("2026-06-01 08:00") в часовом поясе ресторана; null или "" — без расписания.
+type publishScheduleInput struct {
+	PublishAt   string `json:"publish_at"`
+	UnpublishAt string `json:"unpublish_at"`
+}
+
+func parsePublishSchedule(c *fiber.Ctx) (*time.Time, *time.Time, error) {
+	var body publishScheduleInput
+	if err := c.BodyParser(&body); err != nil {
+		return nil, nil, &utils.ValidationError{Message: "Неверный формат тела запроса"}
+	}
+	return utils.ParsePublishSchedule(body.PublishAt, body.UnpublishAt)
+}
+
+// ⏰ Запланировать публикацию и снятие с публикации блюда
+// Тело: {"publish_at": "2026-06-01 08:00", "unpublish_at": null}
+func ScheduleMenuItemPublishing(c *fiber.Ctx) error {
+	id := c.Params("id")
+	publishAt, unpublishAt, err := parsePublishSchedule(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.First(&item, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+
+	item.PublishAt = publishAt
+	item.UnpublishAt = unpublishAt
+	if err := database.DB.Model(&item).Select("publish_at", "unpublish_at").Updates(&item).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить расписание публикации",
+		})
+	}
+	if err := utils.ApplyPublishSchedule(database.DB); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось применить расписание публикации",
+		})
+	}
+
+	database.DB.First(&item, "id = ?", id)
+	return c.JSON(item)
+}
+
+// ⏰ Запланировать открытие и скрытие категории (вместе с подкатегориями и блюдами)
+// Тело: {"publish_at": "2026-12-01 00:00", "unpublish_at": "2027-03-01 00:00"}
+func ScheduleCategoryPublishing(c *fiber.Ctx) error {
+	id := c.Params("id")
+	publishAt, unpublishAt, err := parsePublishSchedule(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var category models.Category
+	if err := database.DB.First(&category, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Категория не найдена",
+		})
+	}
+
+	category.PublishAt = publishAt
+	category.UnpublishAt = unpublishAt
+	if err := database.DB.Model(&category).Select("publish_at", "unpublish_at").Updates(&category).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить расписание публикации",
+		})
+	}
+	if err := utils.ApplyPublishSchedule(database.DB); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось применить расписание публикации",
+		})
+	}
+
+	database.DB.First(&category, "id = ?", id)
+	return c.JSON(category)
+}
+
+// Проверить, что все блюда корзины можно заказать сейчас (в точке корзины, если она выбрана)
+func checkCartOrderable(items []models.CartItem, locationID string) error {
+	reasons, err := utils.CartUnavailableReasons(database.DB, locationID, items)
+	if err != nil {
+		return err
+	}
+
+	var unavailable []string
+	for _, item := range items {
+		if reason, ok := reasons[item.ID]; ok {
+			unavailable = append(unavailable, item.Name+" ("+reason+")")
+		}
+	}
+	if len(unavailable) > 0 {
+		return &utils.ValidationError{Message: "сейчас недоступны для заказа: " + strings.Join(unavailable, ", ")}
+	}
+	return nil
+}
+
+// Ошибки проверки — 400, остальные — 500
+func orderableError(c *fiber.Ctx, err error) error {
+	var verr *utils.ValidationError
+	if errors.As(err, &verr) {
+		return c.Status(400).JSON(fiber.Map{"error": verr.Message})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "не удалось проверить доступность блюд"})
+}
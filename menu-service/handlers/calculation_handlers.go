@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// 🧮 Предпросмотр калькуляции: расчёт на сервере без сохранения
+func PreviewCalculation(c *fiber.Ctx) error {
+	var calc models.Calculation
+	if err := c.BodyParser(&calc); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ComputeCalculation(database.DB, &calc); err != nil {
+		return calculationError(c, err)
+	}
+	return c.JSON(calc)
+}
+
+// Ошибки проверки калькуляции — 400, остальные — 500
+func calculationError(c *fiber.Ctx, err error) error {
+	var verr *utils.ValidationError
+	if errors.As(err, &verr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": verr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Не удалось рассчитать калькуляцию",
+	})
+}
+
+// 🗂 История версий техкарты блюда
+func GetCalculationVersions(c *fiber.Ctx) error {
+	versions, err := utils.ListCalculationVersions(database.DB, c.Params("menuItemId"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить версии калькуляции",
+		})
+	}
+	return c.JSON(versions)
+}
+
+// 📄 Конкретная версия техкарты
+func GetCalculationVersion(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный номер версии",
+		})
+	}
+
+	calc, err := utils.GetCalculationVersion(database.DB, c.Params("menuItemId"), version)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Версия калькуляции не найдена",
+		})
+	}
+	return c.JSON(calc)
+}
+
+// 🔀 Разница между версиями: ?from=1&to=2
+func DiffCalculationVersions(c *fiber.Ctx) error {
+	menuItemID := c.Params("menuItemId")
+	fromVersion := c.QueryInt("from")
+	toVersion := c.QueryInt("to")
+	if fromVersion <= 0 || toVersion <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Параметры from и to обязательны",
+		})
+	}
+
+	from, err := utils.GetCalculationVersion(database.DB, menuItemID, fromVersion)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Версия калькуляции не найдена",
+		})
+	}
+	to, err := utils.GetCalculationVersion(database.DB, menuItemID, toVersion)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Версия калькуляции не найдена",
+		})
+	}
+
+	diff, err := utils.DiffCalculations(from, to)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(diff)
+}
+
+// ⏪ Откатить техкарту к версии — создаётся новая версия с прежним составом
+func RollbackCalculation(c *fiber.Ctx) error {
+	menuItemID := c.Params("menuItemId")
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный номер версии",
+		})
+	}
+
+	calc, err := utils.RollbackCalculation(database.DB, menuItemID, version, currentUserID(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Версия калькуляции не найдена",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось откатить калькуляцию",
+		})
+	}
+
+	syncDishCostByID(menuItemID)
+	refreshStopListFor(menuItemID)
+	return c.Status(fiber.StatusCreated).JSON(calc)
+}
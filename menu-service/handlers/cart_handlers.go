@@ -1,18 +1,42 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 	"monolith/menu-service/database"
 	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
 )
 
-// Тело запроса на добавление товара
+// Тело запроса на добавление товара.
+// Название и цена берутся из меню на сервере, клиент передаёт только выбор.
 type AddToCartBody struct {
-	MenuItemID string  `json:"menuItemId"`
-	Name       string  `json:"name"`
-	Quantity   int     `json:"quantity"`
-	Price      float64 `json:"price"`
+	MenuItemID string   `json:"menuItemId"`
+	Quantity   int      `json:"quantity"`
+	Modifiers  []string `json:"modifiers"`  // ID выбранных опций модификаторов
+	LocationID string   `json:"locationId"` // точка (ID или код); можно передать и как ?location=
+
+	// Комбо вместо блюда: ID комбо и выбор в слотах (ID слота → ID блюда)
+	ComboID    string            `json:"comboId"`
+	ComboItems map[string]string `json:"comboItems"`
+}
+
+// Корзина из другой точки — 409, остальное — 500
+func cartLocationError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, utils.ErrCartOtherLocation) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "не удалось выбрать точку"})
+}
+
+// Точка корзины ("" — общее меню сети)
+func cartLocation(cart models.Cart) string {
+	if cart.LocationID == nil {
+		return ""
+	}
+	return *cart.LocationID
 }
 
 // 📦 Получить корзину пользователя
@@ -28,6 +52,14 @@ func GetCart(c *fiber.Ctx) error {
 
 	// Подтягиваем ImageURL для каждой позиции
 	for i := range cart.Items {
+		if cart.Items[i].ComboID != nil {
+			var combo models.Combo
+			if err := database.DB.Select("image_url").
+				First(&combo, "id = ?", *cart.Items[i].ComboID).Error; err == nil {
+				cart.Items[i].ImageURL = combo.ImageURL
+			}
+			continue
+		}
 		var mi models.MenuItem
 		if err := database.DB.
 			Select("image_url").
@@ -37,6 +69,17 @@ func GetCart(c *fiber.Ctx) error {
 		}
 	}
 
+	// Помечаем блюда из стоп-листа и вне расписания
+	if reasons, err := utils.CartUnavailableReasons(database.DB, cartLocation(cart), cart.Items); err == nil {
+		for i := range cart.Items {
+			if reason, ok := reasons[cart.Items[i].ID]; ok {
+				cart.Items[i].Unavailable = true
+				cart.Items[i].UnavailableReason = reason
+				cart.HasUnavailable = true
+			}
+		}
+	}
+
 	return c.JSON(cart)
 }
 
@@ -48,35 +91,55 @@ func AddToCart(c *fiber.Ctx) error {
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "неверный JSON"})
 	}
+	if body.Quantity <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "количество должно быть больше 0"})
+	}
 
 	var cart models.Cart
 	if err := database.DB.FirstOrCreate(&cart, models.Cart{UserID: userID}).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "не удалось найти или создать корзину"})
 	}
 
-	var item models.CartItem
-	err := database.DB.
-		Where("cart_id = ? AND menu_item_id = ?", cart.ID, body.MenuItemID).
-		First(&item).Error
-
-	if err == gorm.ErrRecordNotFound {
-		item = models.CartItem{
-			CartID:     cart.ID,
-			MenuItemID: body.MenuItemID,
-			Name:       body.Name,
-			Quantity:   body.Quantity,
-			Price:      body.Price,
+	// Корзина собирается в одной точке: сменить точку можно только в пустой корзине
+	ref := body.LocationID
+	if ref == "" {
+		ref = c.Query("location")
+	}
+	if ref != "" {
+		location, err := utils.ResolveLocation(database.DB, ref)
+		if err != nil {
+			return locationError(c, err)
 		}
-		if err := database.DB.Create(&item).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "не удалось добавить товар"})
+		if err := utils.SetCartLocation(database.DB, &cart, location.ID); err != nil {
+			return cartLocationError(c, err)
 		}
-	} else if err == nil {
-		item.Quantity += body.Quantity
-		if err := database.DB.Save(&item).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "не удалось обновить товар"})
+	}
+	locationID := cartLocation(cart)
+
+	if body.ComboID != "" {
+		return addComboToCart(c, cart, locationID, body)
+	}
+
+	line, err := utils.PriceCartLine(database.DB, locationID, body.MenuItemID, body.Modifiers)
+	if err != nil {
+		var verr *utils.ValidationError
+		if errors.As(err, &verr) {
+			return c.Status(400).JSON(fiber.Map{"error": verr.Message})
 		}
-	} else {
-		return c.Status(500).JSON(fiber.Map{"error": "ошибка обработки корзины"})
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось проверить блюдо"})
+	}
+
+	item, err := utils.AddCartLine(database.DB, models.CartItem{
+		CartID:       cart.ID,
+		MenuItemID:   body.MenuItemID,
+		Name:         line.MenuItem.Name,
+		Quantity:     body.Quantity,
+		Price:        line.UnitPrice,
+		Modifiers:    line.Modifiers,
+		ModifiersKey: line.ModifiersKey,
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось добавить товар"})
 	}
 
 	// Подтягиваем картинку перед ответом
@@ -89,6 +152,36 @@ func AddToCart(c *fiber.Ctx) error {
 	return c.JSON(item)
 }
 
+// Добавить комбо: одинаковый выбор в слотах складывается в одну позицию
+func addComboToCart(c *fiber.Ctx, cart models.Cart, locationID string, body AddToCartBody) error {
+	line, err := utils.PriceComboLine(database.DB, locationID, body.ComboID, body.ComboItems)
+	if err != nil {
+		var verr *utils.ValidationError
+		if errors.As(err, &verr) {
+			return c.Status(400).JSON(fiber.Map{"error": verr.Message})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось проверить комбо"})
+	}
+
+	comboID := line.Combo.ID
+	item, err := utils.AddCartLine(database.DB, models.CartItem{
+		CartID:       cart.ID,
+		MenuItemID:   comboID,
+		Name:         line.Combo.Name,
+		Quantity:     body.Quantity,
+		Price:        line.UnitPrice,
+		ModifiersKey: line.Key,
+		ComboID:      &comboID,
+		ComboItems:   line.Items,
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось добавить комбо"})
+	}
+
+	item.ImageURL = line.Combo.ImageURL
+	return c.JSON(item)
+}
+
 // ✏️ Обновить количество товара
 func UpdateCartItem(c *fiber.Ctx) error {
 	userID := c.Params("userId")
@@ -107,8 +200,7 @@ func UpdateCartItem(c *fiber.Ctx) error {
 	}
 
 	var item models.CartItem
-	if err := database.DB.
-		Where("cart_id = ? AND menu_item_id = ?", cart.ID, menuItemID).
+	if err := cartLineQuery(c, cart.ID, menuItemID).
 		First(&item).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "товар не найден"})
 	}
@@ -135,6 +227,16 @@ func UpdateCartItem(c *fiber.Ctx) error {
 	return c.JSON(item)
 }
 
+// Позиции блюда в корзине; ?cartItemId= выбирает конкретную позицию,
+// если одно блюдо лежит в корзине с разными модификаторами
+func cartLineQuery(c *fiber.Ctx, cartID uint, menuItemID string) *gorm.DB {
+	query := database.DB.Where("cart_id = ? AND menu_item_id = ?", cartID, menuItemID)
+	if lineID := c.QueryInt("cartItemId"); lineID > 0 {
+		query = query.Where("id = ?", lineID)
+	}
+	return query
+}
+
 // 🚮 Удалить одну позицию из корзины
 func RemoveCartItem(c *fiber.Ctx) error {
 	userID := c.Params("userId")
@@ -145,8 +247,7 @@ func RemoveCartItem(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "корзина не найдена"})
 	}
 
-	if err := database.DB.
-		Where("cart_id = ? AND menu_item_id = ?", cart.ID, menuItemID).
+	if err := cartLineQuery(c, cart.ID, menuItemID).
 		Delete(&models.CartItem{}).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "не удалось удалить товар"})
 	}
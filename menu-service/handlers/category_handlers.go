@@ -1,44 +1,131 @@
 package handlers
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"monolith/menu-service/database"
 	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
 )
 
-// 📥 Получить все категории
+// Сортировка списка категорий
+var categoryListSpec = listSpec{
+	idColumn: "id",
+	sortFields: map[string]sortField{
+		"name":       {column: "name", kind: sortString},
+		"sort_order": {column: "sort_order", kind: sortNumber},
+		"created_at": {column: "created_at", kind: sortTime},
+	},
+	defaultSort: "sort_order",
+}
+
+// Тело создания/обновления категории. Незаданные поля при обновлении не меняются;
+// parent_id: "" переносит категорию на верхний уровень.
+type categoryInput struct {
+	Name        string  `json:"name"`
+	ParentID    *string `json:"parent_id"`
+	SortOrder   *int    `json:"sort_order"`
+	Description *string `json:"description"`
+	Hidden      *bool   `json:"hidden"`
+}
+
+// Применить ввод к категории с проверкой родителя
+func (in categoryInput) apply(category *models.Category) error {
+	category.Name = in.Name
+	if in.ParentID != nil {
+		parentID := strings.TrimSpace(*in.ParentID)
+		if parentID == "" {
+			category.ParentID = nil
+		} else {
+			if err := utils.ValidateCategoryParent(database.DB, category.ID, &parentID); err != nil {
+				return err
+			}
+			category.ParentID = &parentID
+		}
+	}
+	if in.SortOrder != nil {
+		category.SortOrder = *in.SortOrder
+	}
+	if in.Description != nil {
+		category.Description = *in.Description
+	}
+	if in.Hidden != nil {
+		category.Hidden = *in.Hidden
+	}
+	return nil
+}
+
+// Ответ на ошибку проверки категории: 400 для ValidationError, иначе 500
+func categoryInputError(c *fiber.Ctx, err error) error {
+	var vErr *utils.ValidationError
+	if errors.As(err, &vErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Не удалось проверить родительскую категорию",
+	})
+}
+
+// 📥 Получить все категории (с пагинацией и сортировкой по порядку показа)
+// Фильтры: ?parent_id=<id> (или root — верхний уровень), ?hidden=false
 func GetAllCategories(c *fiber.Ctx) error {
-	var categories []models.Category
-	if err := database.DB.Find(&categories).Error; err != nil {
+	q, err := parseListQuery(c, categoryListSpec)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db, err := applyBoolFilter(c, database.DB.Model(&models.Category{}), "hidden", "hidden")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if c.Query("parent_id") == "root" {
+		db = db.Where("parent_id IS NULL")
+	} else {
+		db = applyEqualFilter(c, db, "parent_id", "parent_id")
+	}
+
+	categories := []models.Category{}
+	resp, err := q.fetch(db, categoryListSpec, "", &categories)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Ошибка при получении категорий",
 		})
 	}
-	return c.JSON(categories)
+	if err := utils.TranslateCategories(database.DB, requestLanguage(c), categories); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Ошибка при получении переводов категорий",
+		})
+	}
+	return c.JSON(resp)
 }
 
 // ➕ Создать категорию
 func CreateCategory(c *fiber.Ctx) error {
-	var input models.Category
+	var input categoryInput
 	if err := c.BodyParser(&input); err != nil || input.Name == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Невалидный ввод. Поле name обязательно",
 		})
 	}
 
-	if err := database.DB.Create(&input).Error; err != nil {
+	var category models.Category
+	if err := input.apply(&category); err != nil {
+		return categoryInputError(c, err)
+	}
+	if err := database.DB.Create(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Ошибка при создании категории",
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(input)
+	return c.Status(fiber.StatusCreated).JSON(category)
 }
 
 // ✏️ Обновить категорию
 func UpdateCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
-	var input models.Category
+	var input categoryInput
 	if err := c.BodyParser(&input); err != nil || input.Name == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Неверный ввод. Поле name обязательно",
@@ -52,7 +139,9 @@ func UpdateCategory(c *fiber.Ctx) error {
 		})
 	}
 
-	category.Name = input.Name
+	if err := input.apply(&category); err != nil {
+		return categoryInputError(c, err)
+	}
 	if err := database.DB.Save(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось обновить категорию",
@@ -73,14 +162,104 @@ func DeleteCategory(c *fiber.Ctx) error {
 		})
 	}
 
+	var children int64
+	if err := database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось проверить подкатегории",
+		})
+	}
+	if children > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Сначала перенесите или удалите подкатегории",
+		})
+	}
+
 	if err := database.DB.Delete(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось удалить категорию",
 		})
 	}
+	cleanupOwnerImages(c, models.MediaOwnerCategory, category.ID)
 
 	return c.JSON(fiber.Map{"message": "Категория удалена"})
 }
 
+// Тело массовой сортировки: ID в нужном порядке
+type reorderBody struct {
+	IDs []string `json:"ids"`
+}
+
+// 🔢 Изменить порядок категорий: PUT /api/categories/reorder {"ids": ["...", "..."]}
+func ReorderCategories(c *fiber.Ctx) error {
+	var body reorderBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ReorderCategories(database.DB, body.IDs); err != nil {
+		var vErr *utils.ValidationError
+		if errors.As(err, &vErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось изменить порядок категорий",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Порядок категорий обновлён"})
+}
 
+// 🌳 Дерево меню для витрины: видимые категории с опубликованными блюдами в порядке показа.
+// Диетические фильтры как у /api/menu/published; ?include_empty=true — оставить пустые категории,
+// ?location=<id|code> — меню и цены конкретной точки
+func GetCategoryTree(c *fiber.Ctx) error {
+	filter, err := parseDietaryFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	includeEmpty := c.QueryBool("include_empty", false)
 
+	var categories []models.Category
+	if err := database.DB.Where("hidden = FALSE").Find(&categories).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Ошибка при получении категорий",
+		})
+	}
+	var items []models.MenuItem
+	if err := database.DB.Where("published = TRUE").Find(&items).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить опубликованные блюда",
+		})
+	}
+
+	items, err = filterOrderableNow(items, location)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось проверить расписание блюд",
+		})
+	}
+	items, err = withNutrition(items, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать пищевую ценность",
+		})
+	}
+
+	lang := requestLanguage(c)
+	if err := utils.TranslateMenuItems(database.DB, lang, items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	if err := utils.TranslateCategories(database.DB, lang, categories); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Ошибка при получении переводов категорий",
+		})
+	}
+
+	return c.JSON(utils.BuildCategoryTree(categories, items, includeEmpty))
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Проверка роли администратора по JWT-клеймам
+func isAdmin(c *fiber.Ctx) bool {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	return ok && claims["role"] == "admin"
+}
+
+// ID текущего пользователя из JWT (пустая строка, если токена нет)
+func currentUserID(c *fiber.Ctx) string {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	id, _ := claims["id"].(string)
+	return id
+}
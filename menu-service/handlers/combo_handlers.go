@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// 🍱 Список комбо: опубликованные с доступностью в точке (?location=<id|code>).
+// ?all=true — все комбо, включая снятые с публикации (для администратора)
+func GetCombos(c *fiber.Ctx) error {
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	combos, err := utils.ListCombos(database.DB, !(c.QueryBool("all") && isAdmin(c)))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить комбо",
+		})
+	}
+	if err := utils.AnnotateCombos(database.DB, location, combos); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать комбо",
+		})
+	}
+	return c.JSON(combos)
+}
+
+// 🍱 Комбо со слотами, себестоимостью и маржой
+func GetComboByID(c *fiber.Ctx) error {
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	combo, err := utils.GetCombo(database.DB, c.Params("id"))
+	if err != nil {
+		return comboNotFound(c, err)
+	}
+	combos := []models.Combo{*combo}
+	if err := utils.AnnotateCombos(database.DB, location, combos); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать комбо",
+		})
+	}
+	return c.JSON(combos[0])
+}
+
+func comboNotFound(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Комбо не найдено"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить комбо"})
+}
+
+// Сохранить комбо и вернуть его с расчётом себестоимости
+func saveCombo(c *fiber.Ctx, combo *models.Combo, status int) error {
+	if err := utils.SaveCombo(database.DB, combo); err != nil {
+		var vErr *utils.ValidationError
+		if errors.As(err, &vErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить комбо",
+		})
+	}
+	saved, err := utils.GetCombo(database.DB, combo.ID)
+	if err != nil {
+		return comboNotFound(c, err)
+	}
+	combos := []models.Combo{*saved}
+	utils.AnnotateCombos(database.DB, "", combos)
+	return c.Status(status).JSON(combos[0])
+}
+
+// ➕ Создать комбо вместе со слотами:
+// {"name": "Бизнес-ланч", "price": 450, "slots": [{"name": "Суп", "items": [{"menu_item_id": "...", "surcharge": 0, "is_default": true}]}]}
+func CreateCombo(c *fiber.Ctx) error {
+	var combo models.Combo
+	if err := c.BodyParser(&combo); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	combo.ID = ""
+	if combo.Currency == "" {
+		combo.Currency = models.DefaultCurrency
+	}
+	return saveCombo(c, &combo, fiber.StatusCreated)
+}
+
+// 🛠 Обновить комбо (слоты заменяются целиком)
+func UpdateCombo(c *fiber.Ctx) error {
+	var input models.Combo
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+
+	var combo models.Combo
+	if err := database.DB.First(&combo, "id = ?", c.Params("id")).Error; err != nil {
+		return comboNotFound(c, err)
+	}
+	combo.Name = input.Name
+	combo.Description = input.Description
+	combo.Price = input.Price
+	combo.ImageURL = input.ImageURL
+	combo.SortOrder = input.SortOrder
+	combo.Published = input.Published
+	if input.Currency != "" {
+		combo.Currency = input.Currency
+	}
+	combo.Slots = input.Slots
+	return saveCombo(c, &combo, fiber.StatusOK)
+}
+
+// 📢 Опубликовать или снять с публикации: {"published": true}
+func PublishCombo(c *fiber.Ctx) error {
+	var body struct {
+		Published bool `json:"published"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	result := database.DB.Model(&models.Combo{}).Where("id = ?", c.Params("id")).Update("published", body.Published)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось изменить публикацию комбо",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Комбо не найдено"})
+	}
+	return c.JSON(fiber.Map{"id": c.Params("id"), "published": body.Published})
+}
+
+// ❌ Удалить комбо. Оформленные заказы сохраняют снимок состава
+func DeleteCombo(c *fiber.Ctx) error {
+	id := c.Params("id")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var slotIDs []string
+		if err := tx.Model(&models.ComboSlot{}).Where("combo_id = ?", id).Pluck("id", &slotIDs).Error; err != nil {
+			return err
+		}
+		if len(slotIDs) > 0 {
+			if err := tx.Where("slot_id IN ?", slotIDs).Delete(&models.ComboSlotItem{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("combo_id = ?", id).Delete(&models.ComboSlot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Combo{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить комбо",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Комбо удалено"})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// ID пользователя из токена; без токена — 401
+func requireUser(c *fiber.Ctx) (string, error) {
+	userID := currentUserID(c)
+	if userID == "" {
+		return "", c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Требуется авторизация"})
+	}
+	return userID, nil
+}
+
+// ❤️ Избранные блюда с текущей ценой и доступностью: ?location=<id|code>
+func GetMyFavorites(c *fiber.Ctx) error {
+	userID, err := requireUser(c)
+	if userID == "" {
+		return err
+	}
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	var favorites []models.Favorite
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&favorites).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить избранное"})
+	}
+	ids := make([]string, 0, len(favorites))
+	for _, f := range favorites {
+		ids = append(ids, f.MenuItemID)
+	}
+
+	var items []models.MenuItem
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Find(&items).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить избранное"})
+		}
+	}
+	if err := utils.TranslateMenuItems(database.DB, requestLanguage(c), items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить переводы"})
+	}
+	byID := make(map[string]models.MenuItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	reasons, err := utils.UnavailableReasonsAt(database.DB, location, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось проверить доступность блюд"})
+	}
+	locationMenu, err := utils.LoadLocationMenu(database.DB, location)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить цены точки"})
+	}
+	ratings, err := utils.RatingSummaries(database.DB, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось посчитать рейтинг"})
+	}
+
+	result := make([]models.FavoriteDish, 0, len(favorites))
+	for _, f := range favorites {
+		item, ok := byID[f.MenuItemID]
+		if !ok {
+			continue // блюдо удалено из меню
+		}
+		item.Price = locationMenu.Price(item.ID, item.Price)
+		item.Rating, item.RatingCount = ratings[item.ID].Average, ratings[item.ID].Count
+		dish := models.FavoriteDish{MenuItem: item, AddedAt: f.CreatedAt}
+		if reason, ok := reasons[item.ID]; ok {
+			dish.Unavailable = true
+			dish.UnavailableReason = reason
+		}
+		result = append(result, dish)
+	}
+	return c.JSON(result)
+}
+
+// ❤️ Добавить блюдо в избранное
+func AddFavorite(c *fiber.Ctx) error {
+	userID, err := requireUser(c)
+	if userID == "" {
+		return err
+	}
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", c.Params("menuItemId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+	}
+	favorite := models.Favorite{UserID: userID, MenuItemID: item.ID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось добавить в избранное"})
+	}
+	return c.JSON(fiber.Map{"message": "Блюдо добавлено в избранное"})
+}
+
+// 💔 Убрать блюдо из избранного
+func RemoveFavorite(c *fiber.Ctx) error {
+	userID, err := requireUser(c)
+	if userID == "" {
+		return err
+	}
+	if err := database.DB.
+		Where("user_id = ? AND menu_item_id = ?", userID, c.Params("menuItemId")).
+		Delete(&models.Favorite{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось убрать из избранного"})
+	}
+	return c.JSON(fiber.Map{"message": "Блюдо убрано из избранного"})
+}
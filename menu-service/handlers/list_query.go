@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// 📑 Общий слой параметров для списков: страницы/курсор, сортировка, фильтры.
+//
+//	?page=2&limit=50            — постраничная выдача
+//	?cursor=...&limit=50        — выдача по курсору (next_cursor из прошлого ответа)
+//	?sort=price&order=desc      — поле и направление сортировки
+//
+// Ответ всегда обёрнут в ListResponse с общим количеством записей.
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// Тип значения сортируемого поля — нужен, чтобы восстановить значение из курсора
+type sortKind int
+
+const (
+	sortString sortKind = iota
+	sortNumber
+	sortTime
+)
+
+// Сортируемое поле: имя в API совпадает с JSON-ключом в ответе
+type sortField struct {
+	column string
+	kind   sortKind
+}
+
+// Описание списка: какие поля можно сортировать и как по умолчанию
+type listSpec struct {
+	idColumn    string
+	sortFields  map[string]sortField
+	defaultSort string
+	defaultDesc bool
+}
+
+// Разобранные параметры списка
+type listQuery struct {
+	page   int
+	limit  int
+	sort   string
+	desc   bool
+	cursor *listCursor
+}
+
+// Курсор: поле сортировки, направление и значения последней записи
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Desc  bool            `json:"d"`
+	Value json.RawMessage `json:"v"`
+	ID    string          `json:"id"`
+}
+
+// 📦 Конверт ответа для списков
+type ListResponse struct {
+	Items      interface{} `json:"items"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	Pages      int         `json:"pages"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Разбор page/limit/cursor/sort/order из query-строки
+func parseListQuery(c *fiber.Ctx, spec listSpec) (listQuery, error) {
+	q := listQuery{
+		page:  c.QueryInt("page", 1),
+		limit: c.QueryInt("limit", defaultListLimit),
+		sort:  c.Query("sort", spec.defaultSort),
+		desc:  spec.defaultDesc,
+	}
+	if q.page < 1 {
+		return q, errors.New("параметр page должен быть больше 0")
+	}
+	if q.limit < 1 || q.limit > maxListLimit {
+		return q, fmt.Errorf("параметр limit должен быть от 1 до %d", maxListLimit)
+	}
+	if _, ok := spec.sortFields[q.sort]; !ok {
+		return q, fmt.Errorf("сортировка по полю %q не поддерживается", q.sort)
+	}
+	switch strings.ToLower(c.Query("order")) {
+	case "":
+	case "asc":
+		q.desc = false
+	case "desc":
+		q.desc = true
+	default:
+		return q, errors.New("параметр order должен быть asc или desc")
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := decodeCursor(raw)
+		if err != nil {
+			return q, errors.New("неверный курсор")
+		}
+		if cur.Sort != q.sort || cur.Desc != q.desc {
+			return q, errors.New("курсор не соответствует параметрам сортировки")
+		}
+		q.cursor = cur
+		q.page = 0
+	}
+	return q, nil
+}
+
+// Выполнить запрос списка: посчитать total, отсортировать, отрезать страницу.
+// selectExpr применяется после подсчёта, чтобы COUNT не ломался на JOIN-выборках.
+func (q listQuery) fetch(db *gorm.DB, spec listSpec, selectExpr string, dest interface{}) (ListResponse, error) {
+	resp := ListResponse{Items: dest, Page: q.page, Limit: q.limit}
+
+	if err := db.Session(&gorm.Session{}).Count(&resp.Total).Error; err != nil {
+		return resp, err
+	}
+	resp.Pages = int(math.Ceil(float64(resp.Total) / float64(q.limit)))
+
+	field := spec.sortFields[q.sort]
+	dir := "ASC"
+	cmp := ">"
+	if q.desc {
+		dir = "DESC"
+		cmp = "<"
+	}
+
+	query := db.Session(&gorm.Session{})
+	if selectExpr != "" {
+		query = query.Select(selectExpr)
+	}
+	query = query.Order(fmt.Sprintf("%s %s, %s %s", field.column, dir, spec.idColumn, dir)).Limit(q.limit)
+
+	if q.cursor != nil {
+		value, err := cursorValue(q.cursor.Value, field.kind)
+		if err != nil {
+			return resp, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", field.column, spec.idColumn, cmp),
+			value, value, q.cursor.ID,
+		)
+	} else {
+		query = query.Offset((q.page - 1) * q.limit)
+	}
+
+	if err := query.Scan(dest).Error; err != nil {
+		return resp, err
+	}
+
+	next, err := nextCursor(dest, q)
+	if err != nil {
+		return resp, err
+	}
+	resp.NextCursor = next
+	return resp, nil
+}
+
+// Курсор на следующую страницу строится по последней записи (по её JSON-полям)
+func nextCursor(dest interface{}, q listQuery) (string, error) {
+	raw, err := json.Marshal(dest)
+	if err != nil {
+		return "", err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return "", err
+	}
+	if len(rows) < q.limit {
+		return "", nil
+	}
+
+	last := rows[len(rows)-1]
+	var id string
+	if err := json.Unmarshal(last["id"], &id); err != nil {
+		return "", err
+	}
+	cur, err := json.Marshal(listCursor{Sort: q.sort, Desc: q.desc, Value: last[q.sort], ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cur), nil
+}
+
+func decodeCursor(raw string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var cur listCursor
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return nil, err
+	}
+	return &cur, nil
+}
+
+func cursorValue(raw json.RawMessage, kind sortKind) (interface{}, error) {
+	switch kind {
+	case sortNumber:
+		var v float64
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case sortTime:
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	default:
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
+
+// Фильтр по диапазону: ?min_price=100&max_price=500
+func applyRangeFilter(c *fiber.Ctx, db *gorm.DB, column, minKey, maxKey string) (*gorm.DB, error) {
+	if raw := c.Query(minKey); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return db, fmt.Errorf("параметр %s должен быть числом", minKey)
+		}
+		db = db.Where(column+" >= ?", v)
+	}
+	if raw := c.Query(maxKey); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return db, fmt.Errorf("параметр %s должен быть числом", maxKey)
+		}
+		db = db.Where(column+" <= ?", v)
+	}
+	return db, nil
+}
+
+// Булев фильтр: ?published=true
+func applyBoolFilter(c *fiber.Ctx, db *gorm.DB, column, key string) (*gorm.DB, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return db, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return db, fmt.Errorf("параметр %s должен быть true или false", key)
+	}
+	return db.Where(column+" = ?", v), nil
+}
+
+// Фильтр на точное совпадение: ?category_id=...
+func applyEqualFilter(c *fiber.Ctx, db *gorm.DB, column, key string) *gorm.DB {
+	if raw := c.Query(key); raw != "" {
+		return db.Where(column+" = ?", raw)
+	}
+	return db
+}
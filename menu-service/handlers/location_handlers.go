@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+var locationCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// Точка из ?location=<id|code>; без параметра — общее меню сети ("")
+func requestLocation(c *fiber.Ctx) (string, error) {
+	ref := strings.TrimSpace(c.Query("location"))
+	if ref == "" {
+		return "", nil
+	}
+	location, err := utils.ResolveLocation(database.DB, ref)
+	if err != nil {
+		return "", err
+	}
+	return location.ID, nil
+}
+
+// Неизвестная точка — 404, остальное — 500
+func locationError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Точка не найдена"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить точку"})
+}
+
+// Точка из пути /api/locations/:id (ID или код)
+func pathLocation(c *fiber.Ctx) (*models.Location, error) {
+	return utils.ResolveLocation(database.DB, c.Params("id"))
+}
+
+// Менять меню и склад точки может администратор или менеджер этой точки
+func canManageLocation(c *fiber.Ctx, locationID string) bool {
+	if isAdmin(c) {
+		return true
+	}
+	userID := currentUserID(c)
+	if userID == "" {
+		return false
+	}
+	role, err := utils.StaffRole(database.DB, userID, locationID)
+	return err == nil && role == models.StaffManager
+}
+
+func forbidden(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Недостаточно прав"})
+}
+
+// 🏠 Список точек; ?all=true — вместе с закрытыми (для администратора)
+func GetLocations(c *fiber.Ctx) error {
+	query := database.DB.Order("name")
+	if !(c.QueryBool("all") && isAdmin(c)) {
+		query = query.Where("archived = FALSE")
+	}
+	var locations []models.Location
+	if err := query.Find(&locations).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить точки",
+		})
+	}
+	return c.JSON(locations)
+}
+
+// 🏠 Точка по ID или коду
+func GetLocation(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	return c.JSON(location)
+}
+
+type locationInput struct {
+	Code    *string `json:"code"`
+	Name    *string `json:"name"`
+	Address *string `json:"address"`
+	Phone   *string `json:"phone"`
+}
+
+// Применить поля запроса к точке и проверить результат
+func (in locationInput) apply(location *models.Location) error {
+	if in.Code != nil {
+		location.Code = strings.ToLower(strings.TrimSpace(*in.Code))
+	}
+	if in.Name != nil {
+		location.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Address != nil {
+		location.Address = strings.TrimSpace(*in.Address)
+	}
+	if in.Phone != nil {
+		location.Phone = strings.TrimSpace(*in.Phone)
+	}
+	if !locationCodePattern.MatchString(location.Code) {
+		return errors.New("Код точки: латиница в нижнем регистре, цифры и дефис, до 50 символов")
+	}
+	if location.Name == "" {
+		return errors.New("Название точки обязательно")
+	}
+	return nil
+}
+
+// Код точки уже занят другой точкой
+func locationCodeTaken(code, exceptID string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Location{}).
+		Where("code = ? AND id <> ?", code, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ➕ Создать точку
+func CreateLocation(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	var input locationInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тела запроса"})
+	}
+	var location models.Location
+	if err := input.apply(&location); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return saveLocation(c, &location, fiber.StatusCreated)
+}
+
+// ✏️ Изменить точку. Закрытие и открытие — через DELETE и /restore
+func UpdateLocation(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	var input locationInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тела запроса"})
+	}
+	if err := input.apply(location); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return saveLocation(c, location, fiber.StatusOK)
+}
+
+func saveLocation(c *fiber.Ctx, location *models.Location, status int) error {
+	taken, err := locationCodeTaken(location.Code, location.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось сохранить точку"})
+	}
+	if taken {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Точка с таким кодом уже есть"})
+	}
+	if err := database.DB.Save(location).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось сохранить точку"})
+	}
+	return c.Status(status).JSON(location)
+}
+
+// 🗄 Закрыть точку. Заказы и история остаются, новые заказы не принимаются
+func DeleteLocation(c *fiber.Ctx) error {
+	return setLocationArchived(c, true)
+}
+
+// ♻️ Снова открыть закрытую точку
+func RestoreLocation(c *fiber.Ctx) error {
+	return setLocationArchived(c, false)
+}
+
+func setLocationArchived(c *fiber.Ctx, archived bool) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if err := database.DB.Model(location).Update("archived", archived).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось изменить точку"})
+	}
+	return c.JSON(location)
+}
+
+// 💲 Цены и скрытые блюда точки
+func GetLocationMenu(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	var rows []models.LocationMenuItem
+	if err := database.DB.Where("location_id = ?", location.ID).Find(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить меню точки"})
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.MenuItemID)
+	}
+	var items []models.MenuItem
+	if len(ids) > 0 {
+		database.DB.Select("id", "name").Where("id IN ?", ids).Find(&items)
+	}
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+	for i := range rows {
+		rows[i].Name = names[rows[i].MenuItemID]
+	}
+	return c.JSON(rows)
+}
+
+// ✏️ Цена блюда в точке и продаётся ли оно: PUT /api/locations/:id/menu/:menuItemId {"price": 390, "hidden": false}
+// "price": null — базовая цена блюда
+func SetLocationMenuItem(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if !canManageLocation(c, location.ID) {
+		return forbidden(c)
+	}
+
+	var row models.LocationMenuItem
+	if err := c.BodyParser(&row); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тела запроса"})
+	}
+	if row.Price != nil && *row.Price < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Цена не может быть отрицательной"})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.Select("id", "name").First(&item, "id = ?", c.Params("menuItemId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+	}
+
+	row.LocationID = location.ID
+	row.MenuItemID = item.ID
+	row.UpdatedBy = currentUserID(c)
+	row.UpdatedAt = time.Now()
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "location_id"}, {Name: "menu_item_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "hidden", "updated_by", "updated_at"}),
+	}).Create(&row).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось сохранить меню точки"})
+	}
+	row.Name = item.Name
+	return c.JSON(row)
+}
+
+// ❌ Вернуть блюду базовую цену и доступность в точке
+func DeleteLocationMenuItem(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if !canManageLocation(c, location.ID) {
+		return forbidden(c)
+	}
+	if err := database.DB.
+		Where("location_id = ? AND menu_item_id = ?", location.ID, c.Params("menuItemId")).
+		Delete(&models.LocationMenuItem{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось изменить меню точки"})
+	}
+	return c.JSON(fiber.Map{"message": "Настройки блюда в точке сброшены"})
+}
+
+// 📦 Остатки склада точки
+func GetLocationStock(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	var rows []models.LocationStock
+	if err := database.DB.Where("location_id = ?", location.ID).Find(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить остатки точки"})
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.InventoryItemID)
+	}
+	var products []models.InventoryItem
+	if len(ids) > 0 {
+		database.DB.Select("id", "product_name").Where("id IN ?", ids).Find(&products)
+	}
+	names := make(map[string]string, len(products))
+	for _, p := range products {
+		names[p.ID] = p.ProductName
+	}
+	for i := range rows {
+		rows[i].ProductName = names[rows[i].InventoryItemID]
+	}
+	return c.JSON(rows)
+}
+
+// ✏️ Остаток продукта в точке: PUT /api/locations/:id/stock/:inventoryItemId {"weight_grams": 5000}
+func SetLocationStock(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if !canManageLocation(c, location.ID) {
+		return forbidden(c)
+	}
+
+	var row models.LocationStock
+	if err := c.BodyParser(&row); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тела запроса"})
+	}
+	if row.WeightGrams < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Остаток не может быть отрицательным"})
+	}
+
+	var product models.InventoryItem
+	if err := database.DB.Select("id", "product_name").First(&product, "id = ?", c.Params("inventoryItemId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Продукт не найден"})
+	}
+
+	row.LocationID = location.ID
+	row.InventoryItemID = product.ID
+	row.UpdatedBy = currentUserID(c)
+	row.UpdatedAt = time.Now()
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "location_id"}, {Name: "inventory_item_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"weight_grams", "updated_by", "updated_at"}),
+	}).Create(&row).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось сохранить остаток"})
+	}
+	row.ProductName = product.ProductName
+	return c.JSON(row)
+}
+
+// ❌ Убрать остаток точки — снова действует общий склад
+func DeleteLocationStock(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if !canManageLocation(c, location.ID) {
+		return forbidden(c)
+	}
+	if err := database.DB.
+		Where("location_id = ? AND inventory_item_id = ?", location.ID, c.Params("inventoryItemId")).
+		Delete(&models.LocationStock{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось удалить остаток"})
+	}
+	return c.JSON(fiber.Map{"message": "Остаток точки удалён"})
+}
+
+// 👥 Сотрудники точки
+func GetLocationStaff(c *fiber.Ctx) error {
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if !canManageLocation(c, location.ID) {
+		return forbidden(c)
+	}
+	var staff []models.StaffAssignment
+	if err := database.DB.Where("location_id = ?", location.ID).Order("role, created_at").Find(&staff).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить сотрудников"})
+	}
+	return c.JSON(staff)
+}
+
+// ✏️ Назначить роль в точке: PUT /api/locations/:id/staff/:userId {"role": "cook"}
+func AssignLocationStaff(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тела запроса"})
+	}
+	body.Role = strings.ToLower(strings.TrimSpace(body.Role))
+	if !models.IsStaffRole(body.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Роль должна быть одной из: manager, cook, waiter, courier",
+		})
+	}
+
+	assignment := models.StaffAssignment{
+		UserID:     c.Params("userId"),
+		LocationID: location.ID,
+		Role:       body.Role,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "location_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&assignment).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось назначить роль"})
+	}
+	database.DB.First(&assignment, "user_id = ? AND location_id = ?", assignment.UserID, location.ID)
+	return c.JSON(assignment)
+}
+
+// ❌ Снять сотрудника с точки
+func RemoveLocationStaff(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	location, err := pathLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	if err := database.DB.
+		Where("location_id = ? AND user_id = ?", location.ID, c.Params("userId")).
+		Delete(&models.StaffAssignment{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось снять сотрудника"})
+	}
+	return c.JSON(fiber.Map{"message": "Сотрудник снят с точки"})
+}
+
+// 👤 Точки и роли текущего пользователя
+func GetMyLocations(c *fiber.Ctx) error {
+	userID := currentUserID(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Требуется авторизация"})
+	}
+
+	type myLocation struct {
+		models.Location
+		Role string `json:"role"`
+	}
+	result := []myLocation{}
+	if err := database.DB.Table("staff_assignments").
+		Select("locations.*, staff_assignments.role").
+		Joins("JOIN locations ON locations.id = staff_assignments.location_id").
+		Where("staff_assignments.user_id = ?", userID).
+		Order("locations.name").
+		Scan(&result).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить точки"})
+	}
+	return c.JSON(result)
+}
+
+// 📊 Продажи по точкам и по сети: ?from=2025-01-01&to=2025-01-31 (обе даты включительно).
+// По умолчанию — последние 30 дней
+func GetLocationSalesReport(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := utils.LocationSalesReport(database.DB, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось построить отчёт"})
+	}
+	report.To = to
+	return c.JSON(report)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/media"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// 🖼 Загрузить изображение блюда (multipart, поле image)
+func UploadMenuItemImage(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+	}
+	return uploadOwnerImage(c, models.MediaOwnerMenuItem, id)
+}
+
+// 🗑 Удалить изображение блюда
+func DeleteMenuItemImage(c *fiber.Ctx) error {
+	return deleteOwnerImage(c, models.MediaOwnerMenuItem, c.Params("id"))
+}
+
+// 🖼 Загрузить изображение категории (multipart, поле image)
+func UploadCategoryImage(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var category models.Category
+	if err := database.DB.Select("id").First(&category, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Категория не найдена"})
+	}
+	return uploadOwnerImage(c, models.MediaOwnerCategory, id)
+}
+
+// 🗑 Удалить изображение категории
+func DeleteCategoryImage(c *fiber.Ctx) error {
+	return deleteOwnerImage(c, models.MediaOwnerCategory, c.Params("id"))
+}
+
+func uploadOwnerImage(c *fiber.Ctx, ownerType, ownerID string) error {
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Передайте файл в поле image (multipart/form-data)",
+		})
+	}
+	limit := media.MaxUploadBytes()
+	if file.Size > limit {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": (&media.UploadError{Message: "Файл слишком большой"}).Error(),
+		})
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Не удалось прочитать файл"})
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Не удалось прочитать файл"})
+	}
+
+	url, variants, err := utils.ReplaceOwnerImage(c.UserContext(), database.DB, media.Default, ownerType, ownerID, data)
+	if err != nil {
+		var uploadErr *media.UploadError
+		if errors.As(err, &uploadErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": uploadErr.Message})
+		}
+		log.Printf("❌ Ошибка загрузки изображения %s %s: %v", ownerType, ownerID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить изображение",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"image_url":      url,
+		"image_variants": variants,
+	})
+}
+
+func deleteOwnerImage(c *fiber.Ctx, ownerType, ownerID string) error {
+	if err := utils.RemoveOwnerImages(c.UserContext(), database.DB, media.Default, ownerType, ownerID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить изображение",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Изображение удалено"})
+}
+
+// Удалить файлы изображений сущности после её удаления; ошибка только логируется
+func cleanupOwnerImages(c *fiber.Ctx, ownerType, ownerID string) {
+	if err := utils.RemoveOwnerImages(c.UserContext(), database.DB, media.Default, ownerType, ownerID); err != nil {
+		log.Printf("⚠️ Не удалось удалить изображения %s %s: %v", ownerType, ownerID, err)
+	}
+}
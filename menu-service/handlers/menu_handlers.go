@@ -2,31 +2,97 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 	"monolith/menu-service/database"
 	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
 )
 
-// 📦 Получить все блюда без категории
+// Сортировка списков блюд
+var menuListSpec = listSpec{
+	idColumn: "menu_items.id",
+	sortFields: map[string]sortField{
+		"name":       {column: "menu_items.name", kind: sortString},
+		"price":      {column: "menu_items.price", kind: sortNumber},
+		"cost_price": {column: "menu_items.cost_price", kind: sortNumber},
+		"margin":     {column: "menu_items.margin", kind: sortNumber},
+		"sort_order": {column: "menu_items.sort_order", kind: sortNumber},
+		"created_at": {column: "menu_items.created_at", kind: sortTime},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
+// Сортировка списка склада
+var inventoryListSpec = listSpec{
+	idColumn: "id",
+	sortFields: map[string]sortField{
+		"product_name": {column: "product_name", kind: sortString},
+		"price_per_kg": {column: "price_per_kg", kind: sortNumber},
+		"weight_grams": {column: "weight_grams", kind: sortNumber},
+		"created_at":   {column: "created_at", kind: sortTime},
+	},
+	defaultSort: "product_name",
+}
+
+// Фильтры блюд: category_id, published, min_price/max_price, min_margin/max_margin
+func applyMenuFilters(c *fiber.Ctx, db *gorm.DB) (*gorm.DB, error) {
+	db = applyEqualFilter(c, db, "menu_items.category_id", "category_id")
+	db, err := applyBoolFilter(c, db, "menu_items.published", "published")
+	if err != nil {
+		return db, err
+	}
+	if db, err = applyRangeFilter(c, db, "menu_items.price", "min_price", "max_price"); err != nil {
+		return db, err
+	}
+	return applyRangeFilter(c, db, "menu_items.margin", "min_margin", "max_margin")
+}
+
+// 📦 Получить все блюда без категории (с пагинацией и фильтрами)
 func GetAllMenuItems(c *fiber.Ctx) error {
-	var items []models.MenuItem
-	if err := database.DB.Find(&items).Error; err != nil {
+	q, err := parseListQuery(c, menuListSpec)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db, err := applyMenuFilters(c, database.DB.Model(&models.MenuItem{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	items := []models.MenuItem{}
+	resp, err := q.fetch(db, menuListSpec, "", &items)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось получить блюда",
 		})
 	}
-	return c.JSON(items)
+	return c.JSON(resp)
 }
 
-// 📂 Получить все опубликованные блюда с названием категории
+// 📂 Получить все опубликованные блюда с названием категории, доступные прямо сейчас
+// Диетические фильтры: ?exclude_allergens=milk,gluten&diet=vegan&max_kcal=600
+// ?location=<id|code> — меню и цены конкретной точки
 func GetPublishedMenuItemsWithCategory(c *fiber.Ctx) error {
+	filter, err := parseDietaryFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+
 	var result []models.MenuItemWithCategory
 
-	err := database.DB.Table("menu_items").
+	err = database.DB.Table("menu_items").
 		Select("menu_items.*, categories.name as category_name").
 		Joins("LEFT JOIN categories ON menu_items.category_id = categories.id").
 		Where("menu_items.published = TRUE").
+		Order("menu_items.sort_order, menu_items.name").
 		Scan(&result).Error
 
 	if err != nil {
@@ -35,46 +101,95 @@ func GetPublishedMenuItemsWithCategory(c *fiber.Ctx) error {
 		})
 	}
 
+	result, err = filterOrderableNowWithCategory(result, location)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось проверить расписание блюд",
+		})
+	}
+	result, err = withNutritionAndCategory(result, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать пищевую ценность",
+		})
+	}
+	if err := utils.TranslateMenuItemsWithCategory(database.DB, requestLanguage(c), result); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+
 	return c.JSON(result)
 }
 
-// 📂 Получить все блюда с названием категории (JOIN)
+// 📂 Получить все блюда с названием категории (JOIN, с пагинацией и фильтрами)
 func GetAllMenuItemsWithCategory(c *fiber.Ctx) error {
-	var result []models.MenuItemWithCategory
-
-	err := database.DB.Table("menu_items").
-		Select("menu_items.*, categories.name as category_name").
-		Joins("LEFT JOIN categories ON menu_items.category_id = categories.id").
-		Scan(&result).Error
+	q, err := parseListQuery(c, menuListSpec)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db, err := applyMenuFilters(c, database.DB.Table("menu_items").
+		Joins("LEFT JOIN categories ON menu_items.category_id = categories.id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
+	result := []models.MenuItemWithCategory{}
+	resp, err := q.fetch(db, menuListSpec, "menu_items.*, categories.name as category_name", &result)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось получить блюда с категориями",
 		})
 	}
 
-	return c.JSON(result)
+	return c.JSON(resp)
 }
 
-// 📦 Получить все опубликованные блюда
+// 📦 Получить опубликованные блюда, доступные прямо сейчас (с КБЖУ и диетическими фильтрами)
+// ?location=<id|code> — меню и цены конкретной точки
 func GetPublishedMenuItems(c *fiber.Ctx) error {
+	filter, err := parseDietaryFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+
 	var items []models.MenuItem
-	if err := database.DB.Where("published = TRUE").Find(&items).Error; err != nil {
+	if err := database.DB.Where("published = TRUE").Order("sort_order, name").Find(&items).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось получить опубликованные блюда",
 		})
 	}
+
+	items, err = filterOrderableNow(items, location)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось проверить расписание блюд",
+		})
+	}
+	items, err = withNutrition(items, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать пищевую ценность",
+		})
+	}
+	if err := utils.TranslateMenuItems(database.DB, requestLanguage(c), items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
 	return c.JSON(items)
 }
 
-// 📊 Получить калькуляцию по блюду
+// 📊 Получить действующую калькуляцию по блюду (вместе с ингредиентами)
 func GetCalculationByMenuItemID(c *fiber.Ctx) error {
 	menuItemID := c.Params("menuItemId")
-	var calc models.Calculation
 
-	if err := database.DB.
-		Where("menu_item_id = ?", menuItemID).
-		First(&calc).Error; err != nil {
+	calc, err := utils.GetCalculationByMenuItemID(database.DB, menuItemID)
+	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Калькуляция не найдена",
 		})
@@ -82,7 +197,7 @@ func GetCalculationByMenuItemID(c *fiber.Ctx) error {
 	return c.JSON(calc)
 }
 
-// 🍽 Получить блюдо по ID
+// 🍽 Получить блюдо по ID (?lang= или Accept-Language — перевод названия и описания)
 func GetMenuItemByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var item models.MenuItem
@@ -92,7 +207,14 @@ func GetMenuItemByID(c *fiber.Ctx) error {
 			"error": "Блюдо не найдено",
 		})
 	}
-	return c.JSON(item)
+
+	items := []models.MenuItem{item}
+	if err := utils.TranslateMenuItems(database.DB, requestLanguage(c), items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(items[0])
 }
 
 // 🍽 Создать новое блюдо
@@ -103,9 +225,17 @@ func CreateMenuItem(c *fiber.Ctx) error {
 			"error": "Неверный формат запроса",
 		})
 	}
-	item.Margin = item.Price - item.CostPrice
 
-	if err := database.DB.Create(&item).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCalculatedCost(tx, &item); err != nil {
+			return err
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return utils.RecordMenuItemPriceChange(tx, models.MenuItem{ID: item.ID}, item, models.PriceSourceCreate, currentUserID(c))
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось сохранить блюдо",
 		})
@@ -123,7 +253,6 @@ func UpdateMenuItem(c *fiber.Ctx) error {
 			"error": "Неверный формат тела запроса",
 		})
 	}
-	input.Margin = input.Price - input.CostPrice
 
 	var item models.MenuItem
 	if err := database.DB.First(&item, "id = ?", id).Error; err != nil {
@@ -132,23 +261,119 @@ func UpdateMenuItem(c *fiber.Ctx) error {
 		})
 	}
 
+	before := item
 	item.Name = input.Name
 	item.Description = input.Description
 	item.Price = input.Price
 	item.CostPrice = input.CostPrice
-	item.Margin = input.Margin
 	item.ImageURL = input.ImageURL
 	item.CategoryID = input.CategoryID
 
-	if err := database.DB.Save(&item).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCalculatedCost(tx, &item); err != nil {
+			return err
+		}
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return utils.RecordMenuItemPriceChange(tx, before, item, models.PriceSourceManual, currentUserID(c))
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось обновить блюдо",
 		})
 	}
-
 	return c.JSON(item)
 }
 
+// 🔢 Изменить порядок блюд: PUT /api/menu/reorder {"ids": ["...", "..."]}
+func ReorderMenuItems(c *fiber.Ctx) error {
+	var body reorderBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ReorderMenuItems(database.DB, body.IDs); err != nil {
+		var vErr *utils.ValidationError
+		if errors.As(err, &vErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось изменить порядок блюд",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Порядок блюд обновлён"})
+}
+
+// Если у блюда есть калькуляция, себестоимость берётся из неё, а не из ввода —
+// до сохранения, чтобы история цен не получала ложных записей «X→0» и «0→X»
+func applyCalculatedCost(tx *gorm.DB, item *models.MenuItem) error {
+	if item.ID != "" {
+		cost, ok, err := utils.ActiveDishCost(tx, item.ID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			item.CostPrice = cost
+		}
+	}
+	item.Margin = item.Price - item.CostPrice
+	return nil
+}
+
+// Пересчитать себестоимость блюда после смены версии техкарты
+func syncDishCostByID(menuItemID string) []models.DishCostChange {
+	changes, err := utils.RecalculateDishCosts(database.DB, []string{menuItemID})
+	if err != nil {
+		log.Printf("⚠️ Не удалось пересчитать себестоимость блюда %s: %v", menuItemID, err)
+	}
+	return changes
+}
+
+// Пересчитать блюда, использующие продукты склада (после изменения цены или названия)
+func recalculateDishesUsing(inventoryItemID string, productNames ...string) []models.DishCostChange {
+	ids, err := utils.MenuItemIDsUsingProduct(database.DB, inventoryItemID, productNames...)
+	if err == nil {
+		var changes []models.DishCostChange
+		if changes, err = utils.RecalculateDishCosts(database.DB, ids); err == nil {
+			return changes
+		}
+	}
+	log.Printf("⚠️ Не удалось пересчитать себестоимость блюд: %v", err)
+	return nil
+}
+
+// 💸 Пересчитать себестоимость блюда по калькуляции
+func RecalculateMenuItemCost(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var item models.MenuItem
+	if err := database.DB.First(&item, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+
+	changes, err := utils.RecalculateDishCosts(database.DB, []string{id})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось пересчитать себестоимость",
+		})
+	}
+	return c.JSON(fiber.Map{"changed": changes})
+}
+
+// 💸 Пересчитать себестоимость всех блюд с калькуляциями
+func RecalculateAllCosts(c *fiber.Ctx) error {
+	changes, err := utils.RecalculateDishCosts(database.DB, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось пересчитать себестоимость",
+		})
+	}
+	return c.JSON(fiber.Map{"changed": changes})
+}
+
 // 🔄 Опубликовать/снять с публикации блюдо
 func PublishMenuItem(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -178,17 +403,46 @@ func DeleteMenuItem(c *fiber.Ctx) error {
 			"error": "Не удалось удалить блюдо",
 		})
 	}
+	cleanupOwnerImages(c, models.MediaOwnerMenuItem, id)
 	return c.JSON(fiber.Map{"message": "Блюдо удалено"})
 }
 
-// 📦 Получить все продукты со склада
+// 📦 Получить продукты со склада: ?available=true&category=овощи&page=1&limit=50
 func GetInventoryItems(c *fiber.Ctx) error {
-	var items []models.InventoryItem
-	if err := database.DB.Find(&items).Error; err != nil {
+	q, err := parseListQuery(c, inventoryListSpec)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db, err := applyBoolFilter(c, database.DB.Model(&models.InventoryItem{}), "available", "available")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db = applyEqualFilter(c, db, "category", "category")
+
+	items := []models.InventoryItem{}
+	resp, err := q.fetch(db, inventoryListSpec, "", &items)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось получить складские продукты",
 		})
 	}
+	if err := utils.TranslateInventoryCategories(database.DB, requestLanguage(c), items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(resp)
+}
+
+// 🏷 Проверка автоматических категорий склада: ?ambiguous=true — только неоднозначные названия,
+// ?changed=true — только те, где классификатор предлагает другую категорию
+func GetInventoryClassification(c *fiber.Ctx) error {
+	items, err := utils.ClassifyInventory(database.DB, c.QueryBool("ambiguous"), c.QueryBool("changed"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось проверить категории склада",
+		})
+	}
 	return c.JSON(items)
 }
 
@@ -200,11 +454,16 @@ func CreateInventoryItem(c *fiber.Ctx) error {
 			"error": "Неверный формат тела запроса",
 		})
 	}
+	if err := validateAllergens(item.Allergens); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err := database.DB.Create(&item).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось добавить продукт на склад",
 		})
 	}
+	item.AffectedDishes = recalculateDishesUsing(item.ID, item.ProductName)
+	refreshStopListUsing(item.ID, item.ProductName)
 	return c.Status(fiber.StatusCreated).JSON(item)
 }
 
@@ -218,6 +477,9 @@ func UpdateInventoryItem(c *fiber.Ctx) error {
 			"error": "Неверный формат тела запроса",
 		})
 	}
+	if err := validateAllergens(input.Allergens); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	var item models.InventoryItem
 	if err := database.DB.First(&item, "id = ?", id).Error; err != nil {
@@ -226,33 +488,58 @@ func UpdateInventoryItem(c *fiber.Ctx) error {
 		})
 	}
 
+	oldName, oldPrice := item.ProductName, item.PricePerKg
+
 	item.ProductName = input.ProductName
 	item.WeightGrams = input.WeightGrams
 	item.PricePerKg = input.PricePerKg
 	item.Available = input.Available
 	item.Emoji = input.Emoji
 	item.Category = input.Category
+	item.KcalPer100g = input.KcalPer100g
+	item.ProteinPer100g = input.ProteinPer100g
+	item.FatPer100g = input.FatPer100g
+	item.CarbsPer100g = input.CarbsPer100g
+	item.Allergens = input.Allergens
+	item.Vegetarian = input.Vegetarian
+	item.Vegan = input.Vegan
 
 	if err := database.DB.Save(&item).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось обновить продукт",
 		})
 	}
+	if item.PricePerKg != oldPrice || item.ProductName != oldName {
+		item.AffectedDishes = recalculateDishesUsing(item.ID, oldName, item.ProductName)
+	}
+	refreshStopListUsing(item.ID, oldName, item.ProductName)
 	return c.JSON(item)
 }
 
 // 🗑 Удалить продукт со склада
 func DeleteInventoryItem(c *fiber.Ctx) error {
 	id := c.Params("id")
+	// Название нужно до удаления: техкарты ссылаются на продукт и по нему
+	var product models.InventoryItem
+	if err := database.DB.Select("id", "product_name").Limit(1).Find(&product, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить продукт со склада",
+		})
+	}
 	if err := database.DB.Delete(&models.InventoryItem{}, "id = ?", id).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось удалить продукт со склада",
 		})
 	}
+	var names []string
+	if product.ProductName != "" {
+		names = append(names, product.ProductName)
+	}
+	refreshStopListUsing(id, names...)
 	return c.JSON(fiber.Map{"message": "Продукт удалён"})
 }
 
-// 📊 Сохранить калькуляцию
+// 📊 Сохранить калькуляцию — каждое сохранение создаёт новую версию техкарты
 func CreateCalculationForDish(c *fiber.Ctx) error {
 	var calc models.Calculation
 	if err := c.BodyParser(&calc); err != nil {
@@ -260,12 +547,30 @@ func CreateCalculationForDish(c *fiber.Ctx) error {
 			"error": "Неверный формат тела запроса",
 		})
 	}
-	if err := database.DB.Create(&calc).Error; err != nil {
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", calc.MenuItemID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+	if err := utils.ComputeCalculation(database.DB, &calc); err != nil {
+		return calculationError(c, err)
+	}
+
+	calc.AuthorID = currentUserID(c)
+	if err := utils.SaveDishCalculation(database.DB, &calc); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Не удалось сохранить калькуляцию",
 		})
 	}
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Калькуляция сохранена"})
+	changes := syncDishCostByID(calc.MenuItemID)
+	refreshStopListFor(calc.MenuItemID)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":      "Калькуляция сохранена",
+		"id":           calc.ID,
+		"version":      calc.Version,
+		"cost_changes": changes,
+	})
 }
 
 
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// 🍕 Получить группы модификаторов блюда
+func GetModifierGroups(c *fiber.Ctx) error {
+	groups, err := utils.GetModifierGroups(database.DB, c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить модификаторы",
+		})
+	}
+	return c.JSON(groups)
+}
+
+// ➕ Создать группу модификаторов вместе с опциями
+func CreateModifierGroup(c *fiber.Ctx) error {
+	menuItemID := c.Params("id")
+
+	var group models.ModifierGroup
+	if err := c.BodyParser(&group); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ValidateModifierGroup(&group); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", menuItemID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+
+	group.ID = ""
+	group.MenuItemID = menuItemID
+	for i := range group.Options {
+		group.Options[i].ID = ""
+	}
+	if err := database.DB.Create(&group).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить группу модификаторов",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(group)
+}
+
+// 🛠 Обновить группу модификаторов (опции заменяются целиком)
+func UpdateModifierGroup(c *fiber.Ctx) error {
+	groupID := c.Params("groupId")
+
+	var input models.ModifierGroup
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	if err := utils.ValidateModifierGroup(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var group models.ModifierGroup
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&group, "id = ?", groupID).Error; err != nil {
+			return err
+		}
+		group.Name = input.Name
+		group.Required = input.Required
+		group.MinSelect = input.MinSelect
+		group.MaxSelect = input.MaxSelect
+		group.SortOrder = input.SortOrder
+		if err := tx.Save(&group).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.ModifierOption{}).Error; err != nil {
+			return err
+		}
+		group.Options = input.Options
+		for i := range group.Options {
+			group.Options[i].ID = ""
+			group.Options[i].GroupID = group.ID
+		}
+		return tx.Create(&group.Options).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Группа модификаторов не найдена",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось обновить группу модификаторов",
+		})
+	}
+	return c.JSON(group)
+}
+
+// ❌ Удалить группу модификаторов
+func DeleteModifierGroup(c *fiber.Ctx) error {
+	groupID := c.Params("groupId")
+	if err := database.DB.Delete(&models.ModifierGroup{}, "id = ?", groupID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить группу модификаторов",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Группа модификаторов удалена"})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Разбор диетических фильтров: ?exclude_allergens=milk,gluten&diet=vegan&max_kcal=600
+func parseDietaryFilter(c *fiber.Ctx) (utils.DietaryFilter, error) {
+	var f utils.DietaryFilter
+
+	if raw := c.Query("exclude_allergens"); raw != "" {
+		for _, code := range strings.Split(raw, ",") {
+			code = strings.ToLower(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			if !models.IsAllergenCode(code) {
+				return f, fmt.Errorf("неизвестный аллерген %q", code)
+			}
+			f.ExcludeAllergens = append(f.ExcludeAllergens, code)
+		}
+	}
+
+	switch c.Query("diet") {
+	case "":
+	case "vegetarian":
+		f.Vegetarian = true
+	case "vegan":
+		f.Vegan = true
+	default:
+		return f, fmt.Errorf("параметр diet должен быть vegetarian или vegan")
+	}
+
+	if raw := c.Query("max_kcal"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return f, fmt.Errorf("параметр max_kcal должен быть положительным числом")
+		}
+		f.MaxKcal = v
+	}
+	return f, nil
+}
+
+// Проверка кодов аллергенов продукта склада
+func validateAllergens(codes []string) error {
+	for _, code := range codes {
+		if !models.IsAllergenCode(code) {
+			return fmt.Errorf("неизвестный аллерген %q", code)
+		}
+	}
+	return nil
+}
+
+// Подставить КБЖУ в блюда и отфильтровать по диете
+func withNutrition(items []models.MenuItem, filter utils.DietaryFilter) ([]models.MenuItem, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	nutrition, err := utils.CalculateNutrition(database.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MenuItem, 0, len(items))
+	for _, item := range items {
+		item.Nutrition = nutrition[item.ID]
+		if filter.Match(item.Nutrition) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// То же для блюд с категорией
+func withNutritionAndCategory(items []models.MenuItemWithCategory, filter utils.DietaryFilter) ([]models.MenuItemWithCategory, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	nutrition, err := utils.CalculateNutrition(database.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MenuItemWithCategory, 0, len(items))
+	for _, item := range items {
+		item.Nutrition = nutrition[item.ID]
+		if filter.Match(item.Nutrition) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// 🥦 КБЖУ и аллергены блюда
+func GetMenuItemNutrition(c *fiber.Ctx) error {
+	id := c.Params("id")
+	nutrition, err := utils.CalculateNutrition(database.DB, []string{id})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось рассчитать пищевую ценность",
+		})
+	}
+	n, ok := nutrition[id]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Калькуляция не найдена",
+		})
+	}
+	return c.JSON(n)
+}
+
+// 📋 Справочник аллергенов
+func GetAllergenCodes(c *fiber.Ctx) error {
+	return c.JSON(models.AllergenCodes)
+}
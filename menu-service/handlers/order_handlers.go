@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"monolith/menu-service/database"
 	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
 )
 
 func PlaceOrder(c *fiber.Ctx) error {
@@ -17,25 +19,61 @@ func PlaceOrder(c *fiber.Ctx) error {
 	if len(cart.Items) == 0 {
 		return c.Status(400).JSON(fiber.Map{"error": "корзина пуста"})
 	}
+	if err := checkCartOrderable(cart.Items, cartLocation(cart)); err != nil {
+		return orderableError(c, err)
+	}
+
+	menuItemIDs := make([]string, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.ComboID == nil {
+			menuItemIDs = append(menuItemIDs, item.MenuItemID)
+		}
+		for _, component := range item.ComboItems {
+			menuItemIDs = append(menuItemIDs, component.MenuItemID)
+		}
+	}
+	calcs, err := utils.GetActiveCalculations(database.DB, menuItemIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось получить калькуляции блюд"})
+	}
 
-	var total float64
+	var total models.Money
 	var orderItems []models.OrderItem
 	for _, item := range cart.Items {
-		total += float64(item.Quantity) * item.Price
-		orderItems = append(orderItems, models.OrderItem{
+		total += item.Price.Mul(item.Quantity)
+		if item.ComboID != nil {
+			orderItems = append(orderItems, expandComboLine(item, calcs)...)
+			continue
+		}
+		orderItem := models.OrderItem{
 			MenuItemID: item.MenuItemID,
 			Name:       item.Name,
 			Quantity:   item.Quantity,
 			Price:      item.Price,
-		})
+			Modifiers:  item.Modifiers,
+		}
+		if calc, ok := calcs[item.MenuItemID]; ok {
+			calcID := calc.ID
+			orderItem.CalculationID = &calcID
+			orderItem.CalculationVersion = calc.Version
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+
+	snapshotVersion, err := utils.CurrentSnapshotVersion(database.DB)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось определить версию меню"})
 	}
 
 	order := models.Order{
-		UserID:     userID,
-		CartID:     cart.ID,
-		Items:      orderItems,
-		TotalPrice: total,
-		Status:     "pending",
+		UserID:              userID,
+		CartID:              cart.ID,
+		Items:               orderItems,
+		TotalPrice:          total,
+		Currency:            models.DefaultCurrency,
+		Status:              models.OrderPending,
+		LocationID:          cart.LocationID,
+		MenuSnapshotVersion: snapshotVersion,
 	}
 
 	if err := database.DB.Create(&order).Error; err != nil {
@@ -48,6 +86,33 @@ func PlaceOrder(c *fiber.Ctx) error {
 	return c.Status(201).JSON(order)
 }
 
+// Комбо уходит на кухню отдельными блюдами: у каждой строки своя техкарта,
+// цена — доля цены набора, строки одного набора связаны ComboLine
+func expandComboLine(item models.CartItem, calcs map[string]models.Calculation) []models.OrderItem {
+	shares := utils.SplitComboPrice(item.Price, item.ComboItems)
+	comboLine := uuid.NewString()
+	rows := make([]models.OrderItem, 0, len(item.ComboItems))
+	for i, component := range item.ComboItems {
+		row := models.OrderItem{
+			MenuItemID: component.MenuItemID,
+			Name:       component.Name,
+			Quantity:   item.Quantity,
+			Price:      shares[i],
+			Modifiers:  []models.SelectedModifier{},
+			ComboID:    item.ComboID,
+			ComboName:  item.Name,
+			ComboLine:  comboLine,
+		}
+		if calc, ok := calcs[component.MenuItemID]; ok {
+			calcID := calc.ID
+			row.CalculationID = &calcID
+			row.CalculationVersion = calc.Version
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 func GetUserOrders(c *fiber.Ctx) error {
 	userID := c.Params("userId")
 
@@ -62,3 +127,69 @@ func GetUserOrders(c *fiber.Ctx) error {
 
 	return c.JSON(orders)
 }
+
+// 🚚 Сменить статус заказа: PUT /api/orders/:id/status {"status": "delivered"}
+// Администратор — для любого заказа, сотрудник точки — для заказов своей точки
+func UpdateOrderStatus(c *fiber.Ctx) error {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&body); err != nil || !models.IsOrderStatus(body.Status) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "статус должен быть одним из: pending, cooking, delivering, delivered, cancelled",
+		})
+	}
+
+	var order models.Order
+	if err := database.DB.First(&order, c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "заказ не найден"})
+	}
+	if !isAdmin(c) {
+		role := ""
+		if order.LocationID != nil && currentUserID(c) != "" {
+			role, _ = utils.StaffRole(database.DB, currentUserID(c), *order.LocationID)
+		}
+		if role == "" {
+			return forbidden(c)
+		}
+	}
+
+	if err := database.DB.Model(&order).Update("status", body.Status).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось обновить статус заказа"})
+	}
+	return c.JSON(order)
+}
+
+// 🔁 Повторить заказ: POST /api/me/orders/:id/reorder.
+// Позиции добавляются в текущую корзину по сегодняшним ценам; недоступные перечисляются в ответе
+func ReorderMyOrder(c *fiber.Ctx) error {
+	userID, err := requireUser(c)
+	if userID == "" {
+		return err
+	}
+
+	var order models.Order
+	if err := database.DB.Preload("Items").
+		First(&order, "id = ? AND user_id = ?", c.Params("id"), userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "заказ не найден"})
+	}
+
+	var cart models.Cart
+	if err := database.DB.FirstOrCreate(&cart, models.Cart{UserID: userID}).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось найти или создать корзину"})
+	}
+	if order.LocationID != nil {
+		if err := utils.SetCartLocation(database.DB, &cart, *order.LocationID); err != nil {
+			return cartLocationError(c, err)
+		}
+	}
+
+	report, err := utils.ReorderIntoCart(database.DB, &cart, order)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось повторить заказ"})
+	}
+	if err := database.DB.Preload("Items").First(&cart, cart.ID).Error; err == nil {
+		report.Cart = &cart
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Необязательная дата из query-параметра (YYYY-MM-DD, время ресторана)
+func queryDate(c *fiber.Ctx, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", v, utils.RestaurantNow().Location())
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// 📈 История цены и себестоимости блюда: ?from=YYYY-MM-DD&to=YYYY-MM-DD (включительно)
+func GetMenuItemPriceHistory(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	from, err := queryDate(c, "from")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Параметр from должен быть в формате YYYY-MM-DD"})
+	}
+	to, err := queryDate(c, "to")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Параметр to должен быть в формате YYYY-MM-DD"})
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Дата to раньше from"})
+	}
+	if to != nil {
+		next := to.AddDate(0, 0, 1)
+		to = &next
+	}
+
+	history, err := utils.GetPriceHistory(database.DB, c.Params("id"), from, to)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить историю цен"})
+	}
+	return c.JSON(history)
+}
+
+// ⏰ Запланировать цену: POST /api/menu/:id/price-schedule {"price": 390, "effective_at": "2025-01-01T00:00:00+03:00", "comment": "..."}
+func SchedulePriceChange(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	var body struct {
+		Price       models.Money `json:"price"`
+		EffectiveAt time.Time    `json:"effective_at"`
+		Comment     string       `json:"comment"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса (время в формате RFC 3339)",
+		})
+	}
+
+	scheduled, err := utils.SchedulePriceChange(database.DB, c.Params("id"), body.Price, body.EffectiveAt, currentUserID(c), body.Comment)
+	if err != nil {
+		var vErr *utils.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось запланировать цену"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(scheduled)
+}
+
+// 🗓 Плановые изменения цен всех блюд: ?status=pending|applied|cancelled (по умолчанию pending)
+func GetScheduledPriceChanges(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	status := c.Query("status", models.ScheduledPricePending)
+	scheduled := []models.ScheduledPriceChange{}
+	if err := database.DB.Where("status = ?", status).Order("effective_at, created_at").Find(&scheduled).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить плановые цены"})
+	}
+	return c.JSON(scheduled)
+}
+
+// ❌ Отменить плановое изменение цены
+func CancelScheduledPriceChange(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	scheduled, err := utils.CancelScheduledPriceChange(database.DB, c.Params("scheduleId"), currentUserID(c))
+	if err != nil {
+		var vErr *utils.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": vErr.Message})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Плановое изменение не найдено"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось отменить плановое изменение"})
+	}
+	return c.JSON(scheduled)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+const (
+	defaultRecommendationLimit = 5
+	maxRecommendationLimit     = 20
+)
+
+func recommendationLimit(c *fiber.Ctx) int {
+	limit := c.QueryInt("limit", defaultRecommendationLimit)
+	if limit <= 0 || limit > maxRecommendationLimit {
+		limit = defaultRecommendationLimit
+	}
+	return limit
+}
+
+// Перевести названия рекомендованных блюд на язык запроса
+func translateRecommendations(c *fiber.Ctx, recs []models.MenuRecommendation) error {
+	items := make([]models.MenuItem, len(recs))
+	for i := range recs {
+		items[i] = recs[i].MenuItem
+	}
+	if err := utils.TranslateMenuItems(database.DB, requestLanguage(c), items); err != nil {
+		return err
+	}
+	for i := range recs {
+		recs[i].MenuItem = items[i]
+	}
+	return nil
+}
+
+// 💡 «Часто заказывают вместе» для блюда: ?limit=5&location=<id|code>
+func GetMenuItemRecommendations(c *fiber.Ctx) error {
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+	recs, err := utils.RecommendMenuItems(database.DB, location, []string{c.Params("id")}, recommendationLimit(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить рекомендации",
+		})
+	}
+	if err := translateRecommendations(c, recs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(recs)
+}
+
+// 🛒 Что добавить к корзине: рекомендации по всем её блюдам (включая блюда комбо)
+func GetCartSuggestions(c *fiber.Ctx) error {
+	var cart models.Cart
+	if err := database.DB.Preload("Items").First(&cart, "user_id = ?", c.Params("userId")).Error; err != nil {
+		return c.JSON([]models.MenuRecommendation{})
+	}
+
+	var ids []string
+	for _, item := range cart.Items {
+		if item.ComboID == nil {
+			ids = append(ids, item.MenuItemID)
+		}
+		for _, component := range item.ComboItems {
+			ids = append(ids, component.MenuItemID)
+		}
+	}
+	recs, err := utils.RecommendMenuItems(database.DB, cartLocation(cart), ids, recommendationLimit(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось подобрать рекомендации"})
+	}
+	if err := translateRecommendations(c, recs); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "не удалось получить переводы"})
+	}
+	return c.JSON(recs)
+}
+
+// 🔄 Пересчитать рекомендации сейчас, не дожидаясь фоновой задачи
+func RecomputeRecommendations(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	if err := utils.RecomputeAssociations(database.DB); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось пересчитать рекомендации",
+		})
+	}
+	var count int64
+	database.DB.Model(&models.MenuItemAssociation{}).Count(&count)
+	return c.JSON(fiber.Map{"message": "Рекомендации пересчитаны", "rules": count})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/transfer"
+	"monolith/menu-service/utils"
+)
+
+// Период отчёта: ?from=YYYY-MM-DD&to=YYYY-MM-DD, обе даты включительно, по умолчанию последние 30 дней
+func reportPeriod(c *fiber.Ctx) (from, to time.Time, err error) {
+	today := utils.RestaurantNow()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	from, to = today.AddDate(0, 0, -29), today
+	if v := c.Query("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, today.Location()); err != nil {
+			return from, to, errors.New("Параметр from должен быть в формате YYYY-MM-DD")
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, today.Location()); err != nil {
+			return from, to, errors.New("Параметр to должен быть в формате YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return from, to, errors.New("Дата to раньше from")
+	}
+	return from, to, nil
+}
+
+// 📊 Меню-инжиниринг: GET /api/menu/reports/engineering?from=&to=&category_id=&location=&format=csv
+// Классы: star, plowhorse, puzzle, dog. Без format — JSON с итогами и порогами, csv/xlsx — файл
+func GetMenuEngineeringReport(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	var format transfer.Format
+	if raw := c.Query("format"); raw != "" {
+		if format, err = transfer.ParseFormat(raw, ""); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	categoryID := c.Query("category_id")
+	report, err := utils.MenuEngineering(database.DB, from, to.AddDate(0, 0, 1), categoryID, location)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Категория не найдена"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось построить отчёт"})
+	}
+	report.To = to
+	if format == "" || format == transfer.FormatJSON {
+		return c.JSON(report)
+	}
+
+	var buf bytes.Buffer
+	if err := transfer.ExportMenuEngineering(&buf, format, report); err != nil {
+		log.Printf("❌ Ошибка выгрузки меню-инжиниринга: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось выгрузить отчёт"})
+	}
+	filename := fmt.Sprintf("menu-engineering-%s-%s.%s", from.Format("2006-01-02"), to.Format("2006-01-02"), format)
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(buf.Bytes())
+}
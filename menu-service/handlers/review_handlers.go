@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+const (
+	defaultReviewLimit = 20
+	maxReviewLimit     = 100
+)
+
+// ⭐ Одобренные отзывы о блюде и средняя оценка: ?limit=20&offset=0
+func GetMenuItemReviews(c *fiber.Ctx) error {
+	menuItemID := c.Params("id")
+	limit := c.QueryInt("limit", defaultReviewLimit)
+	if limit <= 0 || limit > maxReviewLimit {
+		limit = defaultReviewLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	reviews := []models.Review{}
+	if err := database.DB.
+		Where("menu_item_id = ? AND status = ?", menuItemID, models.ReviewApproved).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&reviews).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить отзывы",
+		})
+	}
+	summaries, err := utils.RatingSummaries(database.DB, []string{menuItemID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось посчитать рейтинг",
+		})
+	}
+
+	// Автор, заказ и данные модерации видны только персоналу
+	for i := range reviews {
+		reviews[i].UserID = ""
+		reviews[i].OrderID = 0
+		reviews[i].ModeratedBy = ""
+		reviews[i].ModerationNote = ""
+	}
+	return c.JSON(fiber.Map{
+		"rating":  summaries[menuItemID],
+		"reviews": reviews,
+	})
+}
+
+// ✍️ Оставить отзыв: POST /api/menu/:id/reviews {"rating": 5, "text": "...", "order_id": 42}
+// order_id необязателен — по умолчанию берётся последний доставленный заказ с блюдом
+func CreateMenuItemReview(c *fiber.Ctx) error {
+	userID := currentUserID(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Требуется авторизация"})
+	}
+	var body struct {
+		Rating  int    `json:"rating"`
+		Text    string `json:"text"`
+		OrderID uint   `json:"order_id"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо не найдено"})
+	}
+
+	review, err := utils.SubmitReview(database.DB, userID, item.ID, body.Rating, body.Text, body.OrderID)
+	if err != nil {
+		var vErr *utils.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		case errors.Is(err, utils.ErrNotVerifiedBuyer):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить отзыв",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(review)
+}
+
+// Отзыв из пути; менять его может автор, а с allowAdmin — и администратор
+func reviewForUpdate(c *fiber.Ctx, allowAdmin bool) (*models.Review, error) {
+	var review models.Review
+	if err := database.DB.First(&review, "id = ?", c.Params("reviewId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Отзыв не найден"})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить отзыв"})
+	}
+	if review.UserID != currentUserID(c) && !(allowAdmin && isAdmin(c)) {
+		return nil, forbidden(c)
+	}
+	return &review, nil
+}
+
+// 📷 Фото к отзыву (multipart, поле image). Отзыв с новым фото снова проходит модерацию
+func UploadReviewPhoto(c *fiber.Ctx) error {
+	review, err := reviewForUpdate(c, false)
+	if review == nil {
+		return err
+	}
+	if err := uploadOwnerImage(c, models.MediaOwnerReview, review.ID); err != nil || c.Response().StatusCode() != fiber.StatusCreated {
+		return err
+	}
+	// Новое фото — на модерацию; если загрузка не удалась, отзыв остаётся в прежнем статусе
+	if err := database.DB.Model(review).Update("status", models.ReviewPending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось обновить отзыв"})
+	}
+	return nil
+}
+
+// 🗑 Удалить фото отзыва
+func DeleteReviewPhoto(c *fiber.Ctx) error {
+	review, err := reviewForUpdate(c, true)
+	if review == nil {
+		return err
+	}
+	return deleteOwnerImage(c, models.MediaOwnerReview, review.ID)
+}
+
+// ❌ Удалить отзыв (автор или администратор)
+func DeleteReview(c *fiber.Ctx) error {
+	review, err := reviewForUpdate(c, true)
+	if review == nil {
+		return err
+	}
+	cleanupOwnerImages(c, models.MediaOwnerReview, review.ID)
+	if err := database.DB.Delete(review).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось удалить отзыв"})
+	}
+	return c.JSON(fiber.Map{"message": "Отзыв удалён"})
+}
+
+// 🛡 Отзывы для модерации: ?status=pending (по умолчанию), ?menu_item_id=
+func GetReviewsForModeration(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	status := c.Query("status", models.ReviewPending)
+	query := database.DB.Order("created_at")
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if id := c.Query("menu_item_id"); id != "" {
+		query = query.Where("menu_item_id = ?", id)
+	}
+	reviews := []models.Review{}
+	if err := query.Find(&reviews).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить отзывы",
+		})
+	}
+	return c.JSON(reviews)
+}
+
+// 🔎 Отзыв вместе с заказом — чтобы персонал мог связаться с гостем
+func GetReviewWithOrder(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	var review models.Review
+	if err := database.DB.First(&review, "id = ?", c.Params("reviewId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Отзыв не найден"})
+	}
+	var order models.Order
+	if err := database.DB.Preload("Items").First(&order, review.OrderID).Error; err != nil {
+		return c.JSON(fiber.Map{"review": review, "order": nil})
+	}
+	return c.JSON(fiber.Map{"review": review, "order": order})
+}
+
+// 🛡 Решение модератора: PUT /api/menu/reviews/:reviewId/moderation {"status": "approved", "note": "..."}
+func ModerateReview(c *fiber.Ctx) error {
+	if !isAdmin(c) {
+		return forbidden(c)
+	}
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	review, err := utils.ModerateReview(database.DB, c.Params("reviewId"), body.Status, currentUserID(c), body.Note)
+	if err != nil {
+		var vErr *utils.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Отзыв не найден"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить решение модератора",
+		})
+	}
+	return c.JSON(review)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// 🔎 Поиск по меню: ?q=...&limit=20
+// Администратор может искать и среди неопубликованных: ?all=true
+// ?location=<id|code> — цены и доступность в конкретной точке
+func SearchMenuItems(c *fiber.Ctx) error {
+	query := c.Query("q")
+	if len([]rune(query)) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Параметр q должен содержать минимум 2 символа",
+		})
+	}
+
+	location, err := requestLocation(c)
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	limit := c.QueryInt("limit", defaultSearchLimit)
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	// ?all=true действует только для администратора: остальным — только доступные сейчас блюда
+	all := c.QueryBool("all") && isAdmin(c)
+	var results []models.MenuSearchResult
+	if all {
+		results, err = utils.SearchMenuItems(database.DB, utils.MenuSearchParams{
+			Query:              query,
+			Limit:              limit,
+			IncludeUnpublished: true,
+		})
+	} else {
+		results, err = searchOrderable(query, limit, location)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось выполнить поиск",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"query":   query,
+		"count":   len(results),
+		"results": results,
+	})
+}
+
+// В публичном поиске — только блюда, доступные прямо сейчас, с рейтингом.
+// Недоступные отбрасываются до лимита: результаты дочитываются страницами, пока не наберётся limit.
+func searchOrderable(query string, limit int, locationID string) ([]models.MenuSearchResult, error) {
+	view, err := loadOrderableView(locationID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]models.MenuSearchResult, 0, limit)
+	ids := make([]string, 0, limit)
+	for offset := 0; len(results) < limit; offset += maxSearchLimit {
+		page, err := utils.SearchMenuItems(database.DB, utils.MenuSearchParams{
+			Query:  query,
+			Limit:  maxSearchLimit,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			if len(results) == limit {
+				break
+			}
+			if view.visible(r.ID, r.CategoryID) {
+				r.StopListed = view.stopped[r.ID]
+				r.Price = view.location.Price(r.ID, r.Price)
+				results = append(results, r)
+				ids = append(ids, r.ID)
+			}
+		}
+		if len(page) < maxSearchLimit {
+			break
+		}
+	}
+	ratings, err := utils.RatingSummaries(database.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Rating = ratings[results[i].ID].Average
+		results[i].RatingCount = ratings[results[i].ID].Count
+	}
+	return results, nil
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Ответ на ошибку работы с черновиком
+func draftError(c *fiber.Ctx, err error, fallback string) error {
+	var vErr *utils.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": vErr.Message})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Черновик не найден"})
+	case errors.Is(err, utils.ErrDraftClosed), errors.Is(err, utils.ErrDraftOutdated):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+	}
+}
+
+// 🗂 Черновики меню: ?status=draft
+func GetMenuDrafts(c *fiber.Ctx) error {
+	db := database.DB.Omit("content").Order("updated_at DESC")
+	if status := c.Query("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	drafts := []models.MenuDraft{}
+	if err := db.Find(&drafts).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить черновики",
+		})
+	}
+	return c.JSON(drafts)
+}
+
+// ➕ Создать черновик — копию текущего меню: POST /api/menu/drafts {"name": "Летнее меню"}
+func CreateMenuDraft(c *fiber.Ctx) error {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Неверный формат тела запроса",
+			})
+		}
+	}
+	draft, err := utils.CreateDraft(database.DB, body.Name, currentUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось создать черновик",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(draft)
+}
+
+// 📄 Черновик с содержимым
+func GetMenuDraft(c *fiber.Ctx) error {
+	var draft models.MenuDraft
+	if err := database.DB.First(&draft, "id = ?", c.Params("draftId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Черновик не найден"})
+	}
+	return c.JSON(draft)
+}
+
+// ✏️ Заменить содержимое черновика целиком: PUT /api/menu/drafts/:draftId {"categories": [...], "items": [...]}
+func UpdateMenuDraft(c *fiber.Ctx) error {
+	var content models.MenuContent
+	if err := c.BodyParser(&content); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	draft, err := utils.EditDraft(database.DB, c.Params("draftId"), func(current *models.MenuContent) error {
+		*current = content
+		return nil
+	})
+	if err != nil {
+		return draftError(c, err, "Не удалось сохранить черновик")
+	}
+	return c.JSON(draft)
+}
+
+// ✏️ Добавить или изменить блюдо в черновике.
+// POST /api/menu/drafts/:draftId/items — новое блюдо, PUT .../items/:itemId — существующее
+func UpsertDraftItem(c *fiber.Ctx) error {
+	var item models.SnapshotItem
+	if err := c.BodyParser(&item); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	item.ID = c.Params("itemId")
+	draft, err := utils.EditDraft(database.DB, c.Params("draftId"), func(content *models.MenuContent) error {
+		for i := range content.Items {
+			if item.ID != "" && content.Items[i].ID == item.ID {
+				content.Items[i] = item
+				return nil
+			}
+		}
+		content.Items = append(content.Items, item)
+		return nil
+	})
+	if err != nil {
+		return draftError(c, err, "Не удалось сохранить блюдо в черновике")
+	}
+	return c.JSON(draft)
+}
+
+// ❌ Убрать блюдо из черновика — после публикации оно будет снято с публикации
+func RemoveDraftItem(c *fiber.Ctx) error {
+	itemID := c.Params("itemId")
+	draft, err := utils.EditDraft(database.DB, c.Params("draftId"), func(content *models.MenuContent) error {
+		for i := range content.Items {
+			if content.Items[i].ID == itemID {
+				content.Items = append(content.Items[:i], content.Items[i+1:]...)
+				return nil
+			}
+		}
+		return &utils.ValidationError{Message: "Блюдо не найдено в черновике"}
+	})
+	if err != nil {
+		return draftError(c, err, "Не удалось изменить черновик")
+	}
+	return c.JSON(draft)
+}
+
+// ✏️ Добавить или изменить категорию в черновике
+func UpsertDraftCategory(c *fiber.Ctx) error {
+	var category models.SnapshotCategory
+	if err := c.BodyParser(&category); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+	category.ID = c.Params("categoryId")
+	draft, err := utils.EditDraft(database.DB, c.Params("draftId"), func(content *models.MenuContent) error {
+		for i := range content.Categories {
+			if category.ID != "" && content.Categories[i].ID == category.ID {
+				content.Categories[i] = category
+				return nil
+			}
+		}
+		content.Categories = append(content.Categories, category)
+		return nil
+	})
+	if err != nil {
+		return draftError(c, err, "Не удалось сохранить категорию в черновике")
+	}
+	return c.JSON(draft)
+}
+
+// ❌ Убрать категорию из черновика; в ней не должно остаться блюд и подкатегорий
+func RemoveDraftCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("categoryId")
+	draft, err := utils.EditDraft(database.DB, c.Params("draftId"), func(content *models.MenuContent) error {
+		for _, item := range content.Items {
+			if item.CategoryID == categoryID {
+				return &utils.ValidationError{Message: "В категории остались блюда"}
+			}
+		}
+		for _, category := range content.Categories {
+			if category.ParentID != nil && *category.ParentID == categoryID {
+				return &utils.ValidationError{Message: "У категории остались подкатегории"}
+			}
+		}
+		for i := range content.Categories {
+			if content.Categories[i].ID == categoryID {
+				content.Categories = append(content.Categories[:i], content.Categories[i+1:]...)
+				return nil
+			}
+		}
+		return &utils.ValidationError{Message: "Категория не найдена в черновике"}
+	})
+	if err != nil {
+		return draftError(c, err, "Не удалось изменить черновик")
+	}
+	return c.JSON(draft)
+}
+
+// 🔀 Что изменится при публикации черновика (относительно живого меню)
+func DiffMenuDraft(c *fiber.Ctx) error {
+	var draft models.MenuDraft
+	if err := database.DB.First(&draft, "id = ?", c.Params("draftId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Черновик не найден"})
+	}
+	live, err := utils.LiveMenuContent(database.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить текущее меню",
+		})
+	}
+	version, err := utils.CurrentSnapshotVersion(database.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить версию меню",
+		})
+	}
+	diff := utils.DiffMenuContent(live, draft.Content)
+	diff.FromVersion = version
+	return c.JSON(diff)
+}
+
+// 🚀 Опубликовать черновик: POST /api/menu/drafts/:draftId/publish?force=true {"comment": "..."}
+func PublishMenuDraft(c *fiber.Ctx) error {
+	var body struct {
+		Comment string `json:"comment"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Неверный формат тела запроса",
+			})
+		}
+	}
+	snapshot, err := utils.PublishDraft(database.DB, c.Params("draftId"), currentUserID(c), body.Comment, c.QueryBool("force", false))
+	if err != nil {
+		return draftError(c, err, "Не удалось опубликовать черновик")
+	}
+	return c.Status(fiber.StatusCreated).JSON(snapshot)
+}
+
+// 🗑 Отменить черновик
+func DiscardMenuDraft(c *fiber.Ctx) error {
+	res := database.DB.Model(&models.MenuDraft{}).
+		Where("id = ? AND status = ?", c.Params("draftId"), models.DraftOpen).
+		Update("status", models.DraftDiscarded)
+	if res.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось отменить черновик",
+		})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Открытый черновик не найден",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Черновик отменён"})
+}
+
+// 📸 История опубликованных снимков меню
+func GetMenuSnapshots(c *fiber.Ctx) error {
+	snapshots, err := utils.ListSnapshots(database.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить версии меню",
+		})
+	}
+	return c.JSON(snapshots)
+}
+
+// 📸 Снимок меню по номеру версии
+func GetMenuSnapshot(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный номер версии"})
+	}
+	snapshot, err := utils.GetSnapshot(database.DB, version)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Версия меню не найдена"})
+	}
+	return c.JSON(snapshot)
+}
+
+// 🔀 Разница между версиями меню: ?from=3&to=5
+func DiffMenuSnapshots(c *fiber.Ctx) error {
+	from, err1 := strconv.Atoi(c.Query("from"))
+	to, err2 := strconv.Atoi(c.Query("to"))
+	if err1 != nil || err2 != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Укажите номера версий: ?from=1&to=2",
+		})
+	}
+	fromSnapshot, err := utils.GetSnapshot(database.DB, from)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Версия меню не найдена"})
+	}
+	toSnapshot, err := utils.GetSnapshot(database.DB, to)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Версия меню не найдена"})
+	}
+	diff := utils.DiffMenuContent(fromSnapshot.Content, toSnapshot.Content)
+	diff.FromVersion, diff.ToVersion = from, to
+	return c.JSON(diff)
+}
+
+// ⏪ Откатить меню к опубликованной версии (создаётся новая версия)
+func RollbackMenuSnapshot(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный номер версии"})
+	}
+	snapshot, err := utils.RollbackMenu(database.DB, version, currentUserID(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Версия меню не найдена"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось откатить меню",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(snapshot)
+}
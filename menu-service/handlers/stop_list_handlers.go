@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Обновить стоп-лист блюд, использующих продукт склада
+func refreshStopListUsing(inventoryItemID string, productNames ...string) {
+	ids, err := utils.MenuItemIDsUsingProduct(database.DB, inventoryItemID, productNames...)
+	if err == nil {
+		err = utils.RefreshStopList(database.DB, ids)
+	}
+	if err != nil {
+		log.Printf("⚠️ Не удалось обновить стоп-лист: %v", err)
+	}
+}
+
+// Обновить стоп-лист блюда после смены техкарты
+func refreshStopListFor(menuItemID string) {
+	if err := utils.RefreshStopList(database.DB, []string{menuItemID}); err != nil {
+		log.Printf("⚠️ Не удалось обновить стоп-лист блюда %s: %v", menuItemID, err)
+	}
+}
+
+// ⛔ Текущий стоп-лист
+func GetStopList(c *fiber.Ctx) error {
+	entries, err := utils.GetStopList(database.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить стоп-лист",
+		})
+	}
+	return c.JSON(entries)
+}
+
+// 🔄 Пересчитать стоп-лист по наличию продуктов
+func RefreshStopList(c *fiber.Ctx) error {
+	if err := utils.RefreshStopList(database.DB, nil); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось пересчитать стоп-лист",
+		})
+	}
+	return GetStopList(c)
+}
+
+// ✋ Ручной режим стоп-листа: {"override": "stop" | "available" | "", "reason": "..."}
+// Пустой override возвращает блюдо под управление автоматики.
+func SetStopListOverride(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var body struct {
+		Override string `json:"override"`
+		Reason   string `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный формат тела запроса",
+		})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+
+	entry, err := utils.SetStopOverride(database.DB, id, body.Override, body.Reason, currentUserID(c))
+	if err != nil {
+		var verr *utils.ValidationError
+		if errors.As(err, &verr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": verr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось изменить стоп-лист",
+		})
+	}
+	return c.JSON(fiber.Map{"entry": entry, "stopped": entry.Stopped()})
+}
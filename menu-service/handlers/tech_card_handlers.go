@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/techcard"
+	"monolith/menu-service/utils"
+)
+
+// 📄 Техкарта блюда в PDF: GET /api/menu/calculation/:menuItemId/tech-card?version=3
+// Без version — действующая версия
+func GetTechCardPDF(c *fiber.Ctx) error {
+	version := c.QueryInt("version")
+	if version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный номер версии"})
+	}
+	card, err := utils.TechCardForDish(database.DB, c.Params("menuItemId"), version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Блюдо или техкарта не найдены"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить техкарту"})
+	}
+	filename := fmt.Sprintf("tech-card-%s-v%d.pdf", card.Dish.ID, card.Calculation.Version)
+	return sendTechCards(c, "Технологическая карта: "+card.Dish.Name, filename, []models.TechCard{*card})
+}
+
+// 📚 Техкарты всех блюд категории (с подкатегориями) одним PDF: GET /api/menu/tech-cards?category_id=...
+func GetCategoryTechCardsPDF(c *fiber.Ctx) error {
+	categoryID := c.Query("category_id")
+	if categoryID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Параметр category_id обязателен"})
+	}
+	category, cards, err := utils.TechCardsForCategory(database.DB, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Категория не найдена"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось получить техкарты"})
+	}
+	if len(cards) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "В категории нет блюд с техкартами"})
+	}
+	filename := fmt.Sprintf("tech-cards-%s.pdf", category.ID)
+	return sendTechCards(c, "Технологические карты: "+category.Name, filename, cards)
+}
+
+func sendTechCards(c *fiber.Ctx, title, filename string, cards []models.TechCard) error {
+	var buf bytes.Buffer
+	if err := techcard.Write(&buf, title, cards); err != nil {
+		log.Printf("❌ Ошибка формирования PDF техкарт: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Не удалось сформировать PDF"})
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/database"
+	"monolith/menu-service/transfer"
+)
+
+// 📥 Импорт: POST /api/menu/import/:entity?dry_run=true (multipart, поле file)
+// Сущности: categories, dishes, inventory, calculations. Формат — ?format= или расширение файла.
+// dry_run=true — только предпросмотр. Если хоть одна строка с ошибкой, ничего не сохраняется.
+func ImportMenuData(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Передайте файл в поле file (multipart/form-data)",
+		})
+	}
+	if file.Size > transfer.MaxImportBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("Файл импорта больше %d МБ", transfer.MaxImportBytes>>20),
+		})
+	}
+	format, err := transfer.ParseFormat(c.Query("format"), file.Filename)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Не удалось прочитать файл"})
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, transfer.MaxImportBytes))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Не удалось прочитать файл"})
+	}
+
+	report, err := transfer.Import(database.DB, transfer.Entity(c.Params("entity")), format, data, transfer.ImportOptions{
+		DryRun:   c.QueryBool("dry_run", false),
+		AuthorID: currentUserID(c),
+	})
+	if err != nil {
+		var fileErr *transfer.FileError
+		if errors.As(err, &fileErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fileErr.Message})
+		}
+		log.Printf("❌ Ошибка импорта %s: %v", c.Params("entity"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось выполнить импорт",
+		})
+	}
+	if len(report.Errors) > 0 && !report.DryRun {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(report)
+	}
+	return c.JSON(report)
+}
+
+// 📤 Экспорт: GET /api/menu/export/:entity?format=xlsx
+// Фильтры: category_id, published (блюда, техкарты), hidden (категории),
+// available и category (склад)
+func ExportMenuData(c *fiber.Ctx) error {
+	entity := c.Params("entity")
+	format, err := transfer.ParseFormat(c.Query("format", string(transfer.FormatCSV)), "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filter := transfer.ExportFilter{
+		CategoryID:        c.Query("category_id"),
+		InventoryCategory: c.Query("category"),
+	}
+	for key, dest := range map[string]**bool{
+		"published": &filter.Published,
+		"hidden":    &filter.Hidden,
+		"available": &filter.Available,
+	} {
+		if raw := c.Query(key); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("параметр %s должен быть true или false", key),
+				})
+			}
+			*dest = &v
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := transfer.Export(database.DB, &buf, transfer.Entity(entity), format, filter); err != nil {
+		var fileErr *transfer.FileError
+		if errors.As(err, &fileErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fileErr.Message})
+		}
+		log.Printf("❌ Ошибка экспорта %s: %v", entity, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось выполнить экспорт",
+		})
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", entity, time.Now().Format("2006-01-02"), format)
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+	"monolith/menu-service/database"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+	"monolith/menu-service/utils/emoji"
+)
+
+// Язык ответа: ?lang= или Accept-Language, с откатом на исходный русский
+func requestLanguage(c *fiber.Ctx) string {
+	lang := utils.NegotiateLanguage(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+	c.Set(fiber.HeaderContentLanguage, lang)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return lang
+}
+
+// Язык из пути для админских эндпоинтов переводов
+func translationLanguage(c *fiber.Ctx) (string, bool) {
+	lang := strings.ToLower(c.Params("lang"))
+	return lang, lang != utils.DefaultLanguage && utils.IsSupportedLanguage(lang)
+}
+
+func unsupportedLanguage(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":     "Язык не поддерживается для переводов",
+		"supported": utils.SupportedLanguages,
+	})
+}
+
+// 🌐 Переводы блюда
+func GetMenuItemTranslations(c *fiber.Ctx) error {
+	var translations []models.MenuItemTranslation
+	if err := database.DB.Where("menu_item_id = ?", c.Params("id")).Order("lang").Find(&translations).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(translations)
+}
+
+// ✏️ Сохранить перевод блюда: PUT /api/menu/:id/translations/:lang {"name": "...", "description": "..."}
+func UpsertMenuItemTranslation(c *fiber.Ctx) error {
+	lang, ok := translationLanguage(c)
+	if !ok {
+		return unsupportedLanguage(c)
+	}
+	var t models.MenuItemTranslation
+	if err := c.BodyParser(&t); err != nil || strings.TrimSpace(t.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный ввод. Поле name обязательно",
+		})
+	}
+
+	var item models.MenuItem
+	if err := database.DB.Select("id").First(&item, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Блюдо не найдено",
+		})
+	}
+
+	t.ID = ""
+	t.MenuItemID = item.ID
+	t.Lang = lang
+	t.UpdatedAt = time.Now()
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "menu_item_id"}, {Name: "lang"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(&t).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить перевод",
+		})
+	}
+	return c.JSON(t)
+}
+
+// ❌ Удалить перевод блюда
+func DeleteMenuItemTranslation(c *fiber.Ctx) error {
+	if err := database.DB.
+		Where("menu_item_id = ? AND lang = ?", c.Params("id"), strings.ToLower(c.Params("lang"))).
+		Delete(&models.MenuItemTranslation{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить перевод",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Перевод удалён"})
+}
+
+// 🌐 Переводы категории
+func GetCategoryTranslations(c *fiber.Ctx) error {
+	var translations []models.CategoryTranslation
+	if err := database.DB.Where("category_id = ?", c.Params("id")).Order("lang").Find(&translations).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(translations)
+}
+
+// ✏️ Сохранить перевод категории: PUT /api/categories/:id/translations/:lang {"name": "...", "description": "..."}
+func UpsertCategoryTranslation(c *fiber.Ctx) error {
+	lang, ok := translationLanguage(c)
+	if !ok {
+		return unsupportedLanguage(c)
+	}
+	var t models.CategoryTranslation
+	if err := c.BodyParser(&t); err != nil || strings.TrimSpace(t.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный ввод. Поле name обязательно",
+		})
+	}
+
+	var category models.Category
+	if err := database.DB.First(&category, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Категория не найдена",
+		})
+	}
+
+	t.ID = ""
+	t.CategoryID = category.ID
+	t.Lang = lang
+	t.UpdatedAt = time.Now()
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "lang"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(&t).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить перевод",
+		})
+	}
+	return c.JSON(t)
+}
+
+// ❌ Удалить перевод категории
+func DeleteCategoryTranslation(c *fiber.Ctx) error {
+	if err := database.DB.
+		Where("category_id = ? AND lang = ?", c.Params("id"), strings.ToLower(c.Params("lang"))).
+		Delete(&models.CategoryTranslation{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось удалить перевод",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Перевод удалён"})
+}
+
+// 🌐 Переводы складских категорий
+func GetInventoryCategoryTranslations(c *fiber.Ctx) error {
+	var translations []models.InventoryCategoryTranslation
+	if err := database.DB.Order("category, lang").Find(&translations).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось получить переводы",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"categories":   emoji.Categories(),
+		"translations": translations,
+	})
+}
+
+// ✏️ Сохранить перевод складской категории: PUT /api/menu/inventory-categories/:category/translations/:lang
+func UpsertInventoryCategoryTranslation(c *fiber.Ctx) error {
+	lang, ok := translationLanguage(c)
+	if !ok {
+		return unsupportedLanguage(c)
+	}
+	category := c.Params("category")
+	known := false
+	for _, cat := range emoji.Categories() {
+		if cat == category {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Складская категория не найдена",
+		})
+	}
+
+	var t models.InventoryCategoryTranslation
+	if err := c.BodyParser(&t); err != nil || strings.TrimSpace(t.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Неверный ввод. Поле name обязательно",
+		})
+	}
+
+	t.ID = ""
+	t.Category = category
+	t.Lang = lang
+	t.UpdatedAt = time.Now()
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}, {Name: "lang"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&t).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось сохранить перевод",
+		})
+	}
+	return c.JSON(t)
+}
+
+// 📊 Полнота переводов по языкам
+func GetTranslationCompleteness(c *fiber.Ctx) error {
+	report, err := utils.TranslationCompletenessReport(database.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Не удалось построить отчёт по переводам",
+		})
+	}
+	return c.JSON(report)
+}
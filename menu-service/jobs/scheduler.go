@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/utils"
+)
+
+// Запустить задачу в фоне: сразу и затем с заданным интервалом
+func every(interval time.Duration, name string, task func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := task(); err != nil {
+				log.Printf("⚠️ Фоновая задача %q завершилась с ошибкой: %v", name, err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ⏰ Фоновые задачи меню
+func Start(db *gorm.DB) {
+	every(time.Minute, "плановая публикация", func() error {
+		return utils.ApplyPublishSchedule(db)
+	})
+	every(time.Minute, "плановые цены", func() error {
+		return utils.ApplyScheduledPrices(db)
+	})
+	// Версии техкарт с будущей датой: при вступлении в силу меняются себестоимость и стоп-лист
+	every(time.Minute, "версии техкарт", func() error {
+		return utils.ActivateCalculations(db)
+	})
+	// Остатки могут меняться не только через API — сверяем стоп-лист регулярно
+	every(5*time.Minute, "стоп-лист", func() error {
+		return utils.RefreshStopList(db, nil)
+	})
+	// Рекомендации «заказывают вместе» меняются медленно — раз в час достаточно
+	every(time.Hour, "рекомендации", func() error {
+		return utils.RecomputeAssociations(db)
+	})
+}
@@ -0,0 +1,148 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"monolith/menu-service/models"
+)
+
+// Ширины адаптивных вариантов; больше исходника изображение не растягиваем
+var VariantWidths = []int{320, 640, 1280}
+
+// Максимальная сторона исходника — защита от «бомб» на миллионы пикселей
+const maxSourceSide = 8000
+
+// Разрешённые форматы загрузки (по сигнатуре файла, а не по расширению)
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+// Ошибка проверки загружаемого файла — отдаётся клиенту как 400
+type UploadError struct {
+	Message string
+}
+
+func (e *UploadError) Error() string { return e.Message }
+
+// Максимальный размер загрузки в байтах (MEDIA_MAX_UPLOAD_MB, по умолчанию 5 МБ)
+func MaxUploadBytes() int64 {
+	mb, err := strconv.Atoi(os.Getenv("MEDIA_MAX_UPLOAD_MB"))
+	if err != nil || mb <= 0 {
+		mb = 5
+	}
+	return int64(mb) << 20
+}
+
+// 🔍 Проверка типа и размеров изображения
+func Validate(data []byte) error {
+	if int64(len(data)) > MaxUploadBytes() {
+		return &UploadError{Message: fmt.Sprintf("Файл больше %d МБ", MaxUploadBytes()>>20)}
+	}
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return &UploadError{Message: "Допустимы только изображения JPEG, PNG, WebP или GIF"}
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return &UploadError{Message: "Не удалось прочитать изображение"}
+	}
+	if cfg.Width > maxSourceSide || cfg.Height > maxSourceSide {
+		return &UploadError{Message: fmt.Sprintf("Изображение больше %dx%d пикселей", maxSourceSide, maxSourceSide)}
+	}
+	return nil
+}
+
+// 🖼 Обработать загрузку: нарезать варианты, закодировать в WebP и сохранить в хранилище.
+// Возвращает варианты (по возрастанию ширины) и ключи сохранённых файлов.
+func ProcessUpload(ctx context.Context, storage Storage, prefix string, data []byte) ([]models.MediaVariant, []string, error) {
+	if err := Validate(data); err != nil {
+		return nil, nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, &UploadError{Message: "Не удалось прочитать изображение"}
+	}
+
+	var (
+		variants []models.MediaVariant
+		keys     []string
+	)
+	for _, width := range variantWidthsFor(src.Bounds().Dx()) {
+		img := resize(src, width)
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			Cleanup(ctx, storage, keys)
+			return nil, nil, err
+		}
+
+		key := fmt.Sprintf("%s/%d.webp", prefix, width)
+		url, err := storage.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "image/webp")
+		if err != nil {
+			Cleanup(ctx, storage, keys)
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		variants = append(variants, models.MediaVariant{
+			Width:  img.Bounds().Dx(),
+			Height: img.Bounds().Dy(),
+			URL:    url,
+		})
+	}
+	return variants, keys, nil
+}
+
+// 🧹 Удалить файлы; ошибки не фатальны — файл мог быть удалён раньше
+func Cleanup(ctx context.Context, storage Storage, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := storage.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ширины для исходника: стандартные уже оригинала, а если оригинал не шире
+// самой большой стандартной ширины — он сам становится старшим вариантом.
+// Каждая ширина встречается один раз: ключ файла строится по ширине.
+func variantWidthsFor(srcWidth int) []int {
+	var widths []int
+	for _, w := range VariantWidths {
+		if w < srcWidth {
+			widths = append(widths, w)
+		}
+	}
+	if largest := VariantWidths[len(VariantWidths)-1]; srcWidth <= largest {
+		widths = append(widths, srcWidth)
+	}
+	return widths
+}
+
+// Масштабирование с сохранением пропорций
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
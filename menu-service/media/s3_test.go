@@ -0,0 +1,317 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Объект в поддельном S3
+type fakeObject struct {
+	body         []byte
+	contentType  string
+	cacheControl string
+}
+
+// 🧪 Поддельный S3 в памяти процесса: PUT, GET и DELETE объектов одного бакета
+type fakeS3 struct {
+	bucket  string
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{bucket: bucket, objects: map[string]fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readS3Body(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeObject{
+			body:         body,
+			contentType:  r.Header.Get("Content-Type"),
+			cacheControl: r.Header.Get("Cache-Control"),
+		}
+		w.Header().Set("ETag", `"fake"`)
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.body)
+	case http.MethodDelete:
+		// Как и S3, удаление отсутствующего объекта — успех
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "NotImplemented", http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) object(key string) (fakeObject, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[key]
+	return obj, ok
+}
+
+// Тело PUT: клиент по HTTP шлёт его в aws-chunked ("размер;подпись\r\nданные\r\n...0\r\n")
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var body []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return body, nil
+		}
+		chunk := make([]byte, size+2) // данные и \r\n
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		body = append(body, chunk[:size]...)
+	}
+}
+
+func newTestS3Storage(t *testing.T, endpoint, bucket string) *S3Storage {
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    bucket,
+		AccessKey: "test",
+		SecretKey: "test-secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return storage
+}
+
+func TestS3StoragePutAndDelete(t *testing.T) {
+	fake, server := newFakeS3(t, "menu")
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	storage := newTestS3Storage(t, endpoint, "menu")
+	ctx := context.Background()
+
+	data := []byte("webp-bytes")
+	got, err := storage.Put(ctx, "menu-items/42/320.webp", bytes.NewReader(data), int64(len(data)), "image/webp")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if want := server.URL + "/menu/menu-items/42/320.webp"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+
+	obj, ok := fake.object("menu-items/42/320.webp")
+	if !ok {
+		t.Fatal("объект не сохранён")
+	}
+	if !bytes.Equal(obj.body, data) {
+		t.Errorf("тело = %q, want %q", obj.body, data)
+	}
+	if obj.contentType != "image/webp" {
+		t.Errorf("Content-Type = %q", obj.contentType)
+	}
+	if !strings.Contains(obj.cacheControl, "immutable") {
+		t.Errorf("Cache-Control = %q", obj.cacheControl)
+	}
+
+	// URL из Put отдаёт сохранённый файл
+	resp, err := http.Get(got)
+	if err != nil {
+		t.Fatalf("GET %s: %v", got, err)
+	}
+	served, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(served, data) {
+		t.Errorf("GET вернул %q", served)
+	}
+
+	if err := storage.Delete(ctx, "menu-items/42/320.webp"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.object("menu-items/42/320.webp"); ok {
+		t.Error("объект не удалён")
+	}
+	if err := storage.Delete(ctx, "menu-items/42/320.webp"); err != nil {
+		t.Errorf("повторный Delete: %v", err)
+	}
+}
+
+func TestS3StoragePublicURL(t *testing.T) {
+	_, server := newFakeS3(t, "menu")
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "menu",
+		PublicURL: "https://cdn.example.com/images/",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	got, err := storage.Put(context.Background(), "a/b.webp", strings.NewReader("x"), 1, "image/webp")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if want := "https://cdn.example.com/images/a/b.webp"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+
+	if _, err := NewS3Storage(S3Config{Endpoint: "localhost:9000"}); err == nil {
+		t.Error("без бакета должна быть ошибка")
+	}
+}
+
+func TestProcessUploadToS3(t *testing.T) {
+	fake, server := newFakeS3(t, "menu")
+	storage := newTestS3Storage(t, strings.TrimPrefix(server.URL, "http://"), "menu")
+
+	variants, keys, err := ProcessUpload(context.Background(), storage, "menu-items/42", testPNG(t, 1600, 800))
+	if err != nil {
+		t.Fatalf("ProcessUpload: %v", err)
+	}
+	wantKeys := []string{"menu-items/42/320.webp", "menu-items/42/640.webp", "menu-items/42/1280.webp"}
+	if !slices.Equal(keys, wantKeys) {
+		t.Fatalf("keys = %v, want %v", keys, wantKeys)
+	}
+	for i, v := range variants {
+		if v.Width != VariantWidths[i] || v.Height != VariantWidths[i]/2 {
+			t.Errorf("вариант %d: %dx%d", i, v.Width, v.Height)
+		}
+		obj, ok := fake.object(keys[i])
+		if !ok || obj.contentType != "image/webp" || len(obj.body) == 0 {
+			t.Errorf("вариант %s не сохранён", keys[i])
+		}
+	}
+
+	if err := Cleanup(context.Background(), storage, keys); err != nil {
+		t.Fatalf("Cleanup: %v", err)
+	}
+	for _, key := range keys {
+		if _, ok := fake.object(key); ok {
+			t.Errorf("%s не удалён", key)
+		}
+	}
+}
+
+func TestVariantWidthsFor(t *testing.T) {
+	tests := []struct {
+		src  int
+		want []int
+	}{
+		{src: 200, want: []int{200}},
+		{src: 320, want: []int{320}},
+		{src: 500, want: []int{320, 500}},
+		{src: 1280, want: []int{320, 640, 1280}},
+		{src: 4000, want: []int{320, 640, 1280}},
+	}
+	for _, tt := range tests {
+		if got := variantWidthsFor(tt.src); !slices.Equal(got, tt.want) {
+			t.Errorf("variantWidthsFor(%d) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+// 🐳 Тот же сценарий против настоящего S3-совместимого хранилища, например локального MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_TEST_ENDPOINT=localhost:9000 S3_TEST_BUCKET=menu S3_TEST_ACCESS_KEY=minioadmin \
+//	S3_TEST_SECRET_KEY=minioadmin go test ./menu-service/media/ -run MinIO
+//
+// Бакет должен существовать; если он открыт на чтение (mc anonymous set download),
+// проверяется и то, что ссылка из Put отдаёт файл.
+func TestS3StorageMinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT не задан")
+	}
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		Bucket:    os.Getenv("S3_TEST_BUCKET"),
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+		UseSSL:    os.Getenv("S3_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	ctx := context.Background()
+
+	key := "menu-service-test/" + strconv.FormatInt(int64(os.Getpid()), 10) + ".txt"
+	data := []byte("menu-service s3 test")
+	got, err := storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "text/plain")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := url.Parse(got); err != nil || !strings.HasSuffix(got, "/"+key) {
+		t.Errorf("URL = %q", got)
+	}
+	if resp, err := http.Get(got); err == nil {
+		served, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && !bytes.Equal(served, data) {
+			t.Errorf("GET вернул %q", served)
+		}
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Errorf("повторный Delete: %v", err)
+	}
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.NRGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
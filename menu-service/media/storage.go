@@ -0,0 +1,58 @@
+package media
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+)
+
+// 🗄 Хранилище файлов изображений
+type Storage interface {
+	// Сохранить файл по ключу и вернуть публичный URL
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	// Удалить файл; отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
+
+// Хранилище, выбранное через MEDIA_STORAGE (local по умолчанию или s3)
+var Default Storage
+
+// Каталог локального хранилища (MEDIA_DIR) — его раздаёт сервер по MEDIA_BASE_URL
+var LocalDir = "./media"
+
+// Префикс URL для локального хранилища (MEDIA_BASE_URL)
+var LocalBaseURL = "/media"
+
+// Инициализация хранилища по переменным окружения
+func Init() {
+	LocalDir = envOr("MEDIA_DIR", LocalDir)
+	LocalBaseURL = envOr("MEDIA_BASE_URL", LocalBaseURL)
+	switch os.Getenv("MEDIA_STORAGE") {
+	case "s3":
+		storage, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+		if err != nil {
+			log.Fatalf("❌ Ошибка подключения к S3: %v", err)
+		}
+		Default = storage
+		log.Println("✅ Хранилище изображений: S3")
+	default:
+		Default = NewLocalStorage(LocalDir, LocalBaseURL)
+		log.Printf("✅ Хранилище изображений: локальный каталог %s", LocalDir)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
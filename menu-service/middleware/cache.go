@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/cache"
+	"monolith/menu-service/utils"
+)
+
+// PublicMenuCache — кэш публичных ответов меню со строгим ETag.
+// Ключ: путь + строка запроса + выбранный язык (ответ зависит от Accept-Language).
+// На If-None-Match с совпадающим ETag отвечает 304 без тела.
+// Административные выборки (?all=true) зависят от роли и не кэшируются.
+func PublicMenuCache() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet || c.QueryBool("all") {
+			return c.Next()
+		}
+		lang := utils.NegotiateLanguage(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+		key := fmt.Sprintf("%s?%s|%s", c.Path(), c.Context().QueryArgs().String(), lang)
+
+		if entry, ok := cache.Default.Get(key); ok {
+			c.Set("X-Cache", "HIT")
+			return sendCached(c, entry)
+		}
+
+		gen := cache.Generation()
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		sum := sha256.Sum256(body)
+		entry := cache.Entry{
+			Body:            body,
+			ETag:            `"` + hex.EncodeToString(sum[:16]) + `"`,
+			ContentType:     string(c.Response().Header.ContentType()),
+			ContentLanguage: string(c.Response().Header.Peek(fiber.HeaderContentLanguage)),
+		}
+		cache.SetIfCurrent(gen, key, entry)
+		c.Set("X-Cache", "MISS")
+		return sendCached(c, entry)
+	}
+}
+
+func sendCached(c *fiber.Ctx, entry cache.Entry) error {
+	c.Set(fiber.HeaderETag, entry.ETag)
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", cache.MaxAge))
+	c.Set(fiber.HeaderVary, fiber.HeaderAcceptLanguage)
+	if entry.ContentLanguage != "" {
+		c.Set(fiber.HeaderContentLanguage, entry.ContentLanguage)
+	}
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), entry.ETag) {
+		c.Response().ResetBody()
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, entry.ContentType)
+	c.Status(fiber.StatusOK)
+	return c.Send(entry.Body)
+}
+
+// If-None-Match может содержать список ETag или *; сравнение слабое (RFC 9110)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
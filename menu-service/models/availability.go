@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// 🕗 Окно доступности блюда или категории.
+// Пример: завтраки по будням 08:00–11:00 — Weekdays [1..5], StartTime "08:00", EndTime "11:00".
+// Пустые поля не ограничивают: без Weekdays — каждый день, без дат — круглый год.
+type AvailabilityWindow struct {
+	ID         string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID *string   `json:"menu_item_id,omitempty" gorm:"type:uuid;index"`
+	CategoryID *string   `json:"category_id,omitempty" gorm:"type:uuid;index"`
+	Weekdays   []int     `json:"weekdays" gorm:"type:jsonb;serializer:json"` // 1 — понедельник … 7 — воскресенье
+	StartTime  string    `json:"start_time" gorm:"type:varchar(5)"`          // "08:00"
+	EndTime    string    `json:"end_time" gorm:"type:varchar(5)"`            // "11:00"; меньше начала — окно через полночь
+	StartDate  string    `json:"start_date" gorm:"type:varchar(10)"`         // "2026-06-01"
+	EndDate    string    `json:"end_date" gorm:"type:varchar(10)"`           // "2026-08-31"
+	CreatedAt  time.Time `json:"created_at"`
+}
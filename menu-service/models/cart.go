@@ -6,7 +6,9 @@ import "gorm.io/gorm"
 type Cart struct {
 	gorm.Model
 	UserID string     `gorm:"not null;index" json:"userId"` // индекс по UserID
+	LocationID *string `gorm:"type:uuid;index" json:"locationId"` // точка, из которой заказ
 	Items  []CartItem `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items"`
+	HasUnavailable bool `gorm:"-" json:"hasUnavailable"` // в корзине есть блюда, которые сейчас нельзя заказать
 }
 
 // 📦 Позиция в корзине
@@ -17,8 +19,15 @@ type CartItem struct {
     MenuItemID string  `gorm:"not null"      json:"menuItemId"`
     Name       string  `gorm:"not null"      json:"name"`
     Quantity   int     `gorm:"not null"      json:"quantity"`
-    Price      float64 `gorm:"not null"      json:"price"`
+    Price      Money   `gorm:"not null"      json:"price"`
 	ImageURL    string  `gorm:"-" json:"imageUrl"` // <— новое поле, не сохраняется в cart_items
+	Modifiers    []SelectedModifier `gorm:"type:jsonb;serializer:json" json:"modifiers"`
+	ModifiersKey string             `gorm:"type:text;default:''" json:"-"` // отсортированные ID опций — различает позиции одного блюда
+	// Комбо: MenuItemID содержит ID комбо, выбранные блюда — в ComboItems
+	ComboID    *string             `gorm:"type:uuid" json:"comboId,omitempty"`
+	ComboItems []SelectedComboItem `gorm:"type:jsonb;serializer:json" json:"comboItems,omitempty"`
+	Unavailable       bool   `gorm:"-" json:"unavailable"`
+	UnavailableReason string `gorm:"-" json:"unavailableReason,omitempty"`
 }
 
 
@@ -28,8 +37,32 @@ type Order struct {
 	UserID     string      `gorm:"not null;index" json:"userId"`
 	CartID     uint        `gorm:"index" json:"cartId"`
 	Items      []OrderItem `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items"`
-	TotalPrice float64     `gorm:"not null" json:"totalPrice"`
+	TotalPrice Money       `gorm:"not null" json:"totalPrice"`
+	Currency   string      `gorm:"type:char(3);default:'RUB'" json:"currency"`
 	Status     string      `gorm:"type:varchar(50);default:'pending'" json:"status"`
+
+	LocationID *string `gorm:"type:uuid;index" json:"locationId"` // точка, принявшая заказ
+
+	// Версия опубликованного снимка меню, по которому оформлен заказ
+	MenuSnapshotVersion int `gorm:"default:0" json:"menuSnapshotVersion"`
+}
+
+// Статусы заказа
+const (
+	OrderPending    = "pending"
+	OrderCooking    = "cooking"
+	OrderDelivering = "delivering"
+	OrderDelivered  = "delivered" // после доставки гость может оставить отзыв
+	OrderCancelled  = "cancelled"
+)
+
+// Допустим ли статус заказа
+func IsOrderStatus(status string) bool {
+	switch status {
+	case OrderPending, OrderCooking, OrderDelivering, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
 }
 
 // 🧾 Позиция в заказе
@@ -39,6 +72,36 @@ type OrderItem struct {
 	MenuItemID string  `gorm:"not null" json:"menuItemId"`
 	Name       string  `gorm:"not null" json:"name"`
 	Quantity   int     `gorm:"not null" json:"quantity"`
-	Price      float64 `gorm:"not null" json:"price"`
+	Price      Money   `gorm:"not null" json:"price"`
+	Modifiers  []SelectedModifier `gorm:"type:jsonb;serializer:json" json:"modifiers"` // снимок выбранных модификаторов для кухни
+
+	// Версия техкарты, действовавшая в момент заказа — для исторического фудкоста
+	CalculationID      *string `gorm:"type:uuid" json:"calculationId,omitempty"`
+	CalculationVersion int     `gorm:"default:0" json:"calculationVersion,omitempty"`
+
+	// Блюдо из комбо: позиции одного набора связаны ComboLine, цена — доля цены набора с доплатой
+	ComboID   *string `gorm:"type:uuid;index" json:"comboId,omitempty"`
+	ComboName string  `gorm:"type:text" json:"comboName,omitempty"`
+	ComboLine string  `gorm:"type:varchar(36)" json:"comboLine,omitempty"`
 }
 
+// 🔁 Позиция повторного заказа: старая и текущая цена или причина, по которой её нельзя добавить
+type ReorderLine struct {
+	MenuItemID string `json:"menuItemId"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+	OldPrice   Money  `json:"oldPrice"`
+	Price      Money  `json:"price,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+
+	DroppedModifiers []string `json:"droppedModifiers,omitempty"` // модификаторы блюд комбо, которые не перенесены
+}
+
+// 🔁 Итог повторного заказа
+type ReorderReport struct {
+	OrderID      uint          `json:"orderId"`
+	Added        []ReorderLine `json:"added"`
+	Unavailable  []ReorderLine `json:"unavailable"`
+	PriceChanged bool          `json:"priceChanged"` // цена хотя бы одной позиции изменилась
+	Cart         *Cart         `json:"cart,omitempty"`
+}
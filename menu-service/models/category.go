@@ -5,8 +5,20 @@ import "time"
 type Category struct {
 	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	Name      string    `gorm:"unique;not null" json:"name"` // 👉 теперь поле уникальное
+	ParentID  *string   `gorm:"type:uuid;index" json:"parent_id"`       // Родительская категория (nil — верхний уровень)
+	SortOrder int       `gorm:"default:0;index" json:"sort_order"`      // Порядок показа среди соседей
+	Description string  `gorm:"type:text" json:"description"`
+	Hidden    bool      `gorm:"default:false" json:"hidden"`            // Скрыта вместе с подкатегориями и блюдами
+	PublishAt   *time.Time `gorm:"index" json:"publish_at"`               // Плановое открытие (hidden = false)
+	UnpublishAt *time.Time `gorm:"index" json:"unpublish_at"`             // Плановое скрытие (hidden = true)
+	ImageURL  string    `gorm:"type:text" json:"image_url"`
+	ImageVariants []MediaVariant `gorm:"type:jsonb;serializer:json" json:"image_variants"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
-
-
+// 🌳 Узел дерева меню для витрины: категория, её подкатегории и блюда в порядке показа
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+	Items    []MenuItem     `json:"items"`
+}
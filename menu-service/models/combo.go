@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// 🍱 Комбо (бизнес-ланч): набор слотов по одной позиции в каждом за общую цену
+type Combo struct {
+	ID          string      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string      `json:"name" gorm:"not null"`
+	Description string      `json:"description" gorm:"type:text"`
+	Price       Money       `json:"price" gorm:"not null"` // цена набора без доплат
+	Currency    string      `json:"currency" gorm:"type:char(3);default:'RUB'"`
+	ImageURL    string      `json:"image_url" gorm:"type:text"`
+	SortOrder   int         `json:"sort_order" gorm:"default:0"`
+	Published   bool        `json:"published" gorm:"default:false;index"`
+	Slots       []ComboSlot `json:"slots" gorm:"foreignKey:ComboID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+
+	// Себестоимость и маржа по калькуляциям блюд: при выборе по умолчанию и разброс по всем вариантам
+	CostPrice   Money  `json:"cost_price" gorm:"-"`
+	Margin      Money  `json:"margin" gorm:"-"`
+	CostMin     Money  `json:"cost_min" gorm:"-"`
+	CostMax     Money  `json:"cost_max" gorm:"-"`
+	Unavailable bool   `json:"unavailable" gorm:"-"` // в каком-то слоте сейчас нечего выбрать
+	Reason      string `json:"unavailable_reason,omitempty" gorm:"-"`
+}
+
+// 🥣 Слот комбо ("Суп", "Горячее", "Напиток") — выбирается ровно одно блюдо
+type ComboSlot struct {
+	ID        string          `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ComboID   string          `json:"combo_id" gorm:"type:uuid;not null;index"`
+	Name      string          `json:"name" gorm:"not null"`
+	SortOrder int             `json:"sort_order" gorm:"default:0"`
+	Items     []ComboSlotItem `json:"items" gorm:"foreignKey:SlotID;constraint:OnDelete:CASCADE"`
+}
+
+// 🍲 Блюдо, которое можно выбрать в слоте, с доплатой
+type ComboSlotItem struct {
+	ID         string `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	SlotID     string `json:"slot_id" gorm:"type:uuid;not null;index"`
+	MenuItemID string `json:"menu_item_id" gorm:"type:uuid;not null;index"`
+	Surcharge  Money  `json:"surcharge" gorm:"default:0"`
+	IsDefault  bool   `json:"is_default" gorm:"default:false"`
+	SortOrder  int    `json:"sort_order" gorm:"default:0"`
+
+	Name      string `json:"name,omitempty" gorm:"-"`
+	CostPrice Money  `json:"cost_price" gorm:"-"`
+	Available bool   `json:"available" gorm:"-"` // можно заказать прямо сейчас
+}
+
+// 🧾 Выбранное в слоте блюдо — снимок для корзины и заказа
+type SelectedComboItem struct {
+	SlotID     string `json:"slot_id"`
+	SlotName   string `json:"slot_name"`
+	MenuItemID string `json:"menu_item_id"`
+	Name       string `json:"name"`
+	BasePrice  Money  `json:"base_price"` // цена блюда отдельно — для раскладки цены комбо по позициям
+	Surcharge  Money  `json:"surcharge"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// 💸 Изменение себестоимости блюда после пересчёта
+type DishCostChange struct {
+	MenuItemID string   `json:"menu_item_id"`
+	Name       string   `json:"name"`
+	OldCost    Money    `json:"old_cost"`
+	NewCost    Money    `json:"new_cost"`
+	OldMargin  Money    `json:"old_margin"`
+	NewMargin  Money    `json:"new_margin"`
+	Delta      Money    `json:"delta"`
+	Missing    []string `json:"missing_products,omitempty"` // ингредиенты, посчитанные по цене из калькуляции
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ❤️ Избранное блюдо пользователя
+type Favorite struct {
+	UserID     string    `json:"user_id" gorm:"type:text;primaryKey"`
+	MenuItemID string    `json:"menu_item_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ❤️ Избранное блюдо в ответе: само блюдо и можно ли заказать его сейчас
+type FavoriteDish struct {
+	MenuItem
+	AddedAt           time.Time `json:"added_at"`
+	Unavailable       bool      `json:"unavailable"`
+	UnavailableReason string    `json:"unavailable_reason,omitempty"`
+}
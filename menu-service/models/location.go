@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// 🏠 Точка (ресторан) сети
+type Location struct {
+	ID        string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Code      string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"` // короткий код для витрины: ?location=center
+	Name      string    `json:"name" gorm:"not null"`
+	Address   string    `json:"address" gorm:"type:text"`
+	Phone     string    `json:"phone"`
+	Archived  bool      `json:"archived" gorm:"default:false;index"` // закрытая точка: не принимает заказы
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 💲 Настройки блюда в конкретной точке: своя цена и/или блюдо не продаётся
+type LocationMenuItem struct {
+	LocationID string    `json:"location_id" gorm:"type:uuid;primaryKey"`
+	MenuItemID string    `json:"menu_item_id" gorm:"type:uuid;primaryKey"`
+	Price      *Money    `json:"price"`                       // nil — базовая цена блюда
+	Hidden     bool      `json:"hidden" gorm:"default:false"` // блюдо не продаётся в этой точке
+	UpdatedBy  string    `json:"updated_by" gorm:"type:text"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Name       string    `json:"name,omitempty" gorm:"-"` // название блюда для ответа
+}
+
+// 📦 Остаток продукта на складе точки. Если записи нет, действует общий склад
+type LocationStock struct {
+	LocationID      string    `json:"location_id" gorm:"type:uuid;primaryKey"`
+	InventoryItemID string    `json:"inventory_item_id" gorm:"type:uuid;primaryKey"`
+	WeightGrams     int       `json:"weight_grams" gorm:"not null;default:0"`
+	UpdatedBy       string    `json:"updated_by" gorm:"type:text"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	ProductName     string    `json:"product_name,omitempty" gorm:"-"`
+}
+
+// Роли сотрудников в точке
+const (
+	StaffManager = "manager"
+	StaffCook    = "cook"
+	StaffWaiter  = "waiter"
+	StaffCourier = "courier"
+)
+
+// Допустима ли роль сотрудника точки
+func IsStaffRole(role string) bool {
+	switch role {
+	case StaffManager, StaffCook, StaffWaiter, StaffCourier:
+		return true
+	}
+	return false
+}
+
+// 👥 Роль пользователя в конкретной точке
+type StaffAssignment struct {
+	ID         string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID     string    `json:"user_id" gorm:"type:text;not null;uniqueIndex:idx_staff_user_location"`
+	LocationID string    `json:"location_id" gorm:"type:uuid;not null;uniqueIndex:idx_staff_user_location;index"`
+	Role       string    `json:"role" gorm:"type:varchar(30);not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// 📊 Продажи точки за период
+type LocationSales struct {
+	LocationID   string `json:"location_id"`
+	LocationName string `json:"location_name"`
+	Orders       int64  `json:"orders"`
+	Revenue      Money  `json:"revenue"`
+	AverageCheck Money  `json:"average_check"`
+	ItemsSold    int64  `json:"items_sold"`
+}
+
+// 📊 Сводный отчёт по всем точкам
+type LocationSalesReport struct {
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Locations []LocationSales `json:"locations"`
+	Total     LocationSales   `json:"total"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// 🖼 Вариант изображения под определённую ширину экрана
+type MediaVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// 🗂 Загруженное изображение: все файлы вариантов, чтобы удалить их вместе с владельцем
+type MediaAsset struct {
+	ID        string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OwnerType string    `json:"owner_type" gorm:"type:varchar(20);not null;index:idx_media_owner"` // menu_item | category | review
+	OwnerID   string    `json:"owner_id" gorm:"type:uuid;not null;index:idx_media_owner"`
+	Keys      []string  `json:"keys" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Типы владельцев изображений
+const (
+	MediaOwnerMenuItem = "menu_item"
+	MediaOwnerCategory = "category"
+	MediaOwnerReview   = "review"
+)
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Классы матрицы Kasavana–Smith: популярность × маржинальный доход
+const (
+	EngineeringStar      = "star"      // популярное и выгодное
+	EngineeringPlowhorse = "plowhorse" // популярное, но с низкой маржой
+	EngineeringPuzzle    = "puzzle"    // выгодное, но продаётся плохо
+	EngineeringDog       = "dog"       // непопулярное и невыгодное
+)
+
+// 📊 Блюдо в отчёте меню-инжиниринга
+type MenuEngineeringItem struct {
+	MenuItemID      string  `json:"menu_item_id"`
+	Name            string  `json:"name"`
+	CategoryID      string  `json:"category_id"`
+	CategoryName    string  `json:"category_name"`
+	Sold            int64   `json:"sold"`
+	MenuMixPercent  float64 `json:"menu_mix_percent"` // доля в продажах категории, %
+	Price           Money   `json:"price"`            // средняя цена продажи (без продаж — текущая)
+	FoodCost        Money   `json:"food_cost"`        // средняя себестоимость порции
+	FoodCostPercent float64 `json:"food_cost_percent"`
+	Margin          Money   `json:"margin"` // маржинальный доход с порции
+	Revenue         Money   `json:"revenue"`
+	TotalFoodCost   Money   `json:"total_food_cost"`
+	TotalMargin     Money   `json:"total_margin"`
+	Popularity      string  `json:"popularity"`    // high | low
+	Profitability   string  `json:"profitability"` // high | low
+	Class           string  `json:"class"`
+	Recommendation  string  `json:"recommendation"`
+}
+
+// 📊 Итоги отчёта и пороги классификации
+type MenuEngineeringSummary struct {
+	Dishes              int            `json:"dishes"`
+	Sold                int64          `json:"sold"`
+	Revenue             Money          `json:"revenue"`
+	FoodCost            Money          `json:"food_cost"`
+	FoodCostPercent     float64        `json:"food_cost_percent"`
+	Margin              Money          `json:"margin"`
+	AverageMargin       Money          `json:"average_margin"`       // порог выгодности: средний маржинальный доход порции
+	PopularityThreshold float64        `json:"popularity_threshold"` // порог популярности: 70% от равной доли, %
+	Classes             map[string]int `json:"classes"`
+}
+
+// 📊 Отчёт меню-инжиниринга за период
+type MenuEngineeringReport struct {
+	From       time.Time              `json:"from"`
+	To         time.Time              `json:"to"`
+	CategoryID string                 `json:"category_id,omitempty"`
+	LocationID string                 `json:"location_id,omitempty"`
+	Items      []MenuEngineeringItem  `json:"items"`
+	Summary    MenuEngineeringSummary `json:"summary"`
+}
@@ -11,13 +11,22 @@ type MenuItem struct {
     ID          string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
     Name        string    `json:"name" gorm:"not null"`
     Description string    `json:"description" gorm:"type:text"`
-    Price       float64   `json:"price" gorm:"not null"`
-    CostPrice   float64   `json:"cost_price" gorm:"not null"`
+    Price       Money     `json:"price" gorm:"not null"`
+    CostPrice   Money     `json:"cost_price" gorm:"not null"`
     ImageURL    string    `json:"image_url" gorm:"type:text"`           // Ссылка на картинку
-    Margin      float64   `json:"margin" gorm:"not null"`               // Рассчитанная на уровне приложения
+    ImageVariants []MediaVariant `json:"image_variants" gorm:"type:jsonb;serializer:json"` // Адаптивные варианты WebP
+    Margin      Money     `json:"margin" gorm:"not null"`               // Рассчитанная на уровне приложения
+    Currency    string    `json:"currency" gorm:"type:char(3);default:'RUB'"`
     CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
     CategoryID  string    `json:"category_id" gorm:"type:uuid;index"`    // FK на категорию
+    SortOrder   int       `json:"sort_order" gorm:"default:0"`           // Порядок внутри категории
     Published   bool      `json:"published" gorm:"default:false;index"`  // Опубликовано ли блюдо
+    PublishAt   *time.Time `json:"publish_at" gorm:"index"`               // Плановая публикация
+    UnpublishAt *time.Time `json:"unpublish_at" gorm:"index"`             // Плановое снятие с публикации
+    Nutrition   *DishNutrition `json:"nutrition,omitempty" gorm:"-"`    // КБЖУ и аллергены из калькуляции
+    StopListed  bool      `json:"stop_listed" gorm:"-"`                  // В стоп-листе — показывается, но недоступно
+    Rating      float64   `json:"rating" gorm:"-"`                       // Средняя оценка по одобренным отзывам
+    RatingCount int       `json:"rating_count" gorm:"-"`                 // Количество одобренных отзывов
 }
 
 // 📂 Меню-блюдо с категорией (JOIN)
@@ -25,14 +34,23 @@ type MenuItemWithCategory struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
 	Description  string    `json:"description"`
-	Price        float64   `json:"price"`
-	CostPrice    float64   `json:"cost_price"`
+	Price        Money     `json:"price"`
+	CostPrice    Money     `json:"cost_price"`
 	ImageURL     string    `json:"image_url"`
-	Margin       float64   `json:"margin"`
+	ImageVariants []MediaVariant `json:"image_variants" gorm:"serializer:json"`
+	Margin       Money     `json:"margin"`
+	Currency     string    `json:"currency"`
 	CreatedAt    time.Time `json:"created_at"`
 	CategoryID   string    `json:"category_id"`
 	CategoryName string    `json:"category_name"`
+	SortOrder    int       `json:"sort_order"`
 	Published    bool      `json:"published"`
+	PublishAt    *time.Time `json:"publish_at"`
+	UnpublishAt  *time.Time `json:"unpublish_at"`
+	Nutrition    *DishNutrition `json:"nutrition,omitempty" gorm:"-"`
+	StopListed   bool      `json:"stop_listed" gorm:"-"`
+	Rating       float64   `json:"rating" gorm:"-"`
+	RatingCount  int       `json:"rating_count" gorm:"-"`
 }
 
 // 📦 Продукт на складе
@@ -40,36 +58,97 @@ type InventoryItem struct {
 	ID          string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	ProductName string    `json:"product_name" gorm:"not null"`
 	WeightGrams int       `json:"weight_grams" gorm:"not null"`
-	PricePerKg  float64   `json:"price_per_kg" gorm:"not null"`
+	PricePerKg  Money     `json:"price_per_kg" gorm:"not null"`
+	Currency    string    `json:"currency" gorm:"type:char(3);default:'RUB'"`
 	Available   bool      `json:"available" gorm:"default:true"`
 	CreatedAt   time.Time `json:"created_at"`
 	Emoji       string    `json:"emoji" gorm:"default:'🍽️'"`
 	Category    *string   `json:"category" gorm:"default:'прочее'"`
+
+	// Пищевая ценность на 100 г и аллергены продукта
+	KcalPer100g    float64  `json:"kcal_per_100g" gorm:"default:0"`
+	ProteinPer100g float64  `json:"protein_per_100g" gorm:"default:0"`
+	FatPer100g     float64  `json:"fat_per_100g" gorm:"default:0"`
+	CarbsPer100g   float64  `json:"carbs_per_100g" gorm:"default:0"`
+	Allergens      []string `json:"allergens" gorm:"type:jsonb;serializer:json"` // null — не проверены, [] — аллергенов нет
+	Vegetarian     bool     `json:"vegetarian" gorm:"default:false"`
+	Vegan          bool     `json:"vegan" gorm:"default:false"`
+
+	AffectedDishes []DishCostChange `json:"affected_dishes,omitempty" gorm:"-"` // блюда, чья себестоимость изменилась
+	CategoryLabel  string           `json:"category_label,omitempty" gorm:"-"`  // название категории на языке запроса
+}
+
+// 🏷 Продукт склада: сохранённая категория и что предлагает классификатор по названию
+type InventoryClassification struct {
+	ID                string   `json:"id"`
+	ProductName       string   `json:"product_name"`
+	Category          string   `json:"category"`
+	Emoji             string   `json:"emoji"`
+	SuggestedCategory string   `json:"suggested_category"`
+	SuggestedEmoji    string   `json:"suggested_emoji"`
+	Confidence        float64  `json:"confidence"`
+	Keyword           string   `json:"keyword,omitempty"`
+	Ambiguous         bool     `json:"ambiguous"`
+	Candidates        []string `json:"candidates"` // другие категории, слова которых есть в названии
+	Changed           bool     `json:"changed"`    // предложение отличается от сохранённой категории
 }
 
 // 📐 Ингредиент в калькуляции
 type CalculationIngredient struct {
 	ID              string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	CalculationID   string    `json:"calculation_id" gorm:"type:uuid;not null;index"`
+	InventoryItemID *string   `json:"inventory_item_id" gorm:"type:uuid;index"` // продукт склада
 	ProductName     string    `json:"product_name" gorm:"not null"`
-	AmountGrams     int       `json:"amount_grams" gorm:"not null"`
-	PricePerKg      float64   `json:"price_per_kg" gorm:"not null"`
+	AmountGrams     int       `json:"amount_grams" gorm:"not null"` // вес брутто
+	NetGrams        float64   `json:"net_grams" gorm:"default:0"`   // вес нетто после отходов
+	PricePerKg      Money     `json:"price_per_kg" gorm:"not null"`
 	WastePercent    float64   `json:"waste_percent" gorm:"default:0.0"`
-	PriceAfterWaste float64   `json:"price_after_waste"`
-	TotalCost       float64   `json:"total_cost"`
+	PriceAfterWaste Money     `json:"price_after_waste"` // цена кг нетто
+	TotalCost       Money     `json:"total_cost"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
 // 🧾 Финальная калькуляция блюда
+// Каждая правка техкарты — новая версия; действует последняя версия с EffectiveFrom <= сейчас.
 type MenuCalculation struct {
 	ID               string                  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	MenuItemID       string                  `json:"menu_item_id" gorm:"type:uuid;not null;index"`
+	Version          int                     `json:"version" gorm:"not null;default:1"`
+	AuthorID         string                  `json:"author_id" gorm:"type:text"`
+	Comment          string                  `json:"comment" gorm:"type:text"`
+	CookingNotes     string                  `json:"cooking_notes" gorm:"type:text"` // технология приготовления, оформление и подача
+	EffectiveFrom    time.Time               `json:"effective_from" gorm:"index"`
+	ActivatedAt      *time.Time              `json:"activated_at" gorm:"index"` // вступила в силу: себестоимость и стоп-лист пересчитаны
+	TotalGrossGrams  int                     `json:"total_gross_grams" gorm:"default:0"`
+	TotalNetGrams    float64                 `json:"total_net_grams" gorm:"default:0"`
 	TotalOutputGrams int                     `json:"total_output_grams"`
-	TotalCost        float64                 `json:"total_cost"`
+	YieldPercent     float64                 `json:"yield_percent" gorm:"default:0"` // выход готового блюда от веса нетто
+	TotalCost        Money                   `json:"total_cost"`
 	Ingredients      []CalculationIngredient `json:"ingredients" gorm:"foreignKey:CalculationID;constraint:OnDelete:CASCADE"`
 	CreatedAt        time.Time               `json:"created_at"`
 }
 
+// 🔀 Изменение ингредиента между версиями техкарты
+type IngredientChange struct {
+	ProductName string                `json:"product_name"`
+	Before      CalculationIngredient `json:"before"`
+	After       CalculationIngredient `json:"after"`
+}
+
+// 🔀 Разница между двумя версиями техкарты
+type CalculationDiff struct {
+	MenuItemID        string                  `json:"menu_item_id"`
+	FromVersion       int                     `json:"from_version"`
+	ToVersion         int                     `json:"to_version"`
+	OutputGramsBefore int                     `json:"output_grams_before"`
+	OutputGramsAfter  int                     `json:"output_grams_after"`
+	TotalCostBefore   Money                   `json:"total_cost_before"`
+	TotalCostAfter    Money                   `json:"total_cost_after"`
+	Added             []CalculationIngredient `json:"added"`
+	Removed           []CalculationIngredient `json:"removed"`
+	Changed           []IngredientChange      `json:"changed"`
+}
+
 // ✅ Псевдоним для совместимости
 type Calculation = MenuCalculation
 
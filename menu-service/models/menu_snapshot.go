@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// 📂 Категория в снимке меню
+type SnapshotCategory struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	ParentID    *string `json:"parent_id"`
+	SortOrder   int     `json:"sort_order"`
+	Description string  `json:"description"`
+	Hidden      bool    `json:"hidden"`
+}
+
+// 🍽 Блюдо в снимке меню
+type SnapshotItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CategoryID  string `json:"category_id"`
+	Price       Money  `json:"price"`
+	Currency    string `json:"currency"`
+	ImageURL    string `json:"image_url"`
+	Published   bool   `json:"published"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+// 📋 Содержимое меню: то, что публикуется снимком и редактируется в черновике
+type MenuContent struct {
+	Categories []SnapshotCategory `json:"categories"`
+	Items      []SnapshotItem     `json:"items"`
+}
+
+// Статусы черновика
+const (
+	DraftOpen      = "draft"
+	DraftPublished = "published"
+	DraftDiscarded = "discarded"
+)
+
+// ✏️ Черновик меню: правки не видны гостям до публикации
+type MenuDraft struct {
+	ID          string      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string      `json:"name" gorm:"not null"`
+	Status      string      `json:"status" gorm:"type:varchar(20);default:'draft';index"`
+	BaseVersion int         `json:"base_version" gorm:"default:0"` // версия снимка, с которой начат черновик
+	AuthorID    string      `json:"author_id" gorm:"type:text"`
+	Content     MenuContent `json:"content" gorm:"type:jsonb;serializer:json"`
+	PublishedAs int         `json:"published_as,omitempty" gorm:"default:0"` // версия снимка после публикации
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// 📸 Опубликованный снимок меню. Версии идут подряд; откат создаёт новую версию
+type MenuSnapshot struct {
+	ID           string      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Version      int         `json:"version" gorm:"not null;uniqueIndex"`
+	DraftID      *string     `json:"draft_id" gorm:"type:uuid"`
+	RollbackFrom int         `json:"rollback_from,omitempty" gorm:"default:0"` // версия, к которой откатились
+	AuthorID     string      `json:"author_id" gorm:"type:text"`
+	Comment      string      `json:"comment" gorm:"type:text"`
+	Content      MenuContent `json:"content,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// 🔀 Изменение поля
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// 🔀 Изменённая сущность (блюдо или категория) с перечнем изменённых полей
+type EntityChange struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// 🔀 Разница между двумя версиями меню
+type MenuDiff struct {
+	FromVersion       int                `json:"from_version"`
+	ToVersion         int                `json:"to_version"` // 0 — черновик
+	AddedItems        []SnapshotItem     `json:"added_items"`
+	RemovedItems      []SnapshotItem     `json:"removed_items"`
+	ChangedItems      []EntityChange     `json:"changed_items"`
+	AddedCategories   []SnapshotCategory `json:"added_categories"`
+	RemovedCategories []SnapshotCategory `json:"removed_categories"`
+	ChangedCategories []EntityChange     `json:"changed_categories"`
+}
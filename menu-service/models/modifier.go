@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 🍕 Группа модификаторов блюда (размер, соус, добавки)
+// MinSelect/MaxSelect — сколько опций можно выбрать (MaxSelect = 0 — без ограничения).
+type ModifierGroup struct {
+	ID         string           `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID string           `json:"menu_item_id" gorm:"type:uuid;not null;index"`
+	Name       string           `json:"name" gorm:"not null"`
+	Required   bool             `json:"required" gorm:"default:false"`
+	MinSelect  int              `json:"min_select" gorm:"default:0"`
+	MaxSelect  int              `json:"max_select" gorm:"default:0"`
+	SortOrder  int              `json:"sort_order" gorm:"default:0"`
+	Options    []ModifierOption `json:"options" gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// ➕ Опция модификатора с надбавкой к цене
+type ModifierOption struct {
+	ID         string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	GroupID    string    `json:"group_id" gorm:"type:uuid;not null;index"`
+	Name       string    `json:"name" gorm:"not null"`
+	PriceDelta Money     `json:"price_delta" gorm:"default:0"`
+	IsDefault  bool      `json:"is_default" gorm:"default:false"`
+	Available  bool      `json:"available" gorm:"default:true"`
+	SortOrder  int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// 🧾 Выбранный модификатор — снимок для корзины и заказа
+type SelectedModifier struct {
+	GroupID    string  `json:"group_id"`
+	GroupName  string  `json:"group_name"`
+	OptionID   string  `json:"option_id"`
+	OptionName string  `json:"option_name"`
+	PriceDelta Money   `json:"price_delta"`
+}
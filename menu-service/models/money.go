@@ -0,0 +1,181 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Валюта меню по умолчанию (ISO 4217)
+const DefaultCurrency = "RUB"
+
+// 💰 Денежная сумма в минимальных единицах (копейках).
+// В базе хранится как NUMERIC(12,2), в JSON — как число с двумя знаками после точки.
+//
+// Правила округления: до копейки, половина — от нуля (0.005 → 0.01).
+// Стоимость по цене за кг считается целочисленно: копейки × граммы / 1000 с тем же округлением;
+// сумма калькуляции — сумма уже округлённых строк.
+type Money int64
+
+// Сумма из рублей с копейками: 350.5 → 35050
+func MoneyFromFloat(v float64) Money {
+	m, err := ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+	if err != nil {
+		return 0
+	}
+	return m
+}
+
+// Разбор десятичной строки "350.505" без потерь float: → 35051
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("пустая сумма")
+	}
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("неверная сумма %q", s)
+		}
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("неверная сумма %q", s)
+	}
+	frac := (fracPart + "000")[:3]
+	cents, _ := strconv.ParseInt(frac[:2], 10, 64)
+	amount := units*100 + cents
+	if frac[2] >= '5' {
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money(amount), nil
+}
+
+// Сумма в рублях (для отчётов и сравнения с float-фильтрами)
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// Строка с двумя знаками: 35050 → "350.50"
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// Стоимость позиции: цена × количество
+func (m Money) Mul(qty int) Money {
+	return m * Money(qty)
+}
+
+// Стоимость веса в граммах по цене за кг
+func (m Money) ForGrams(grams int) Money {
+	return divRound(int64(m)*int64(grams), 1000)
+}
+
+// Доля суммы на n частей (средний чек, цена порции) с округлением половины от нуля; n > 0
+func (m Money) Div(n int64) Money {
+	return divRound(int64(m), n)
+}
+
+// Умножение на дробный коэффициент (например, цена кг нетто с учётом отходов)
+func (m Money) MulFloat(k float64) Money {
+	return MoneyFromFloat(m.Float() * k)
+}
+
+// Деление с округлением половины от нуля
+func divRound(a, b int64) Money {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q)
+}
+
+// Тип колонки для миграций GORM
+func (Money) GormDataType() string {
+	return "numeric(12,2)"
+}
+
+// Запись в базу
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Чтение из базы: NUMERIC приходит строкой, старые double precision — float64
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case float64:
+		*m = MoneyFromFloat(v)
+	case int64:
+		*m = Money(v * 100)
+	default:
+		return fmt.Errorf("неподдерживаемый тип суммы %T", src)
+	}
+	return nil
+}
+
+// JSON-число: 350.50
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Принимает число или строку: 350.5, "350.50"
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
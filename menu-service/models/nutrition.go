@@ -0,0 +1,50 @@
+package models
+
+// 🥦 Коды аллергенов (14 основных пищевых аллергенов)
+var AllergenCodes = []string{
+	"gluten",      // глютен
+	"crustaceans", // ракообразные
+	"eggs",        // яйца
+	"fish",        // рыба
+	"peanuts",     // арахис
+	"soy",         // соя
+	"milk",        // молоко и лактоза
+	"nuts",        // орехи
+	"celery",      // сельдерей
+	"mustard",     // горчица
+	"sesame",      // кунжут
+	"sulphites",   // сульфиты
+	"lupin",       // люпин
+	"molluscs",    // моллюски
+}
+
+// Проверка кода аллергена
+func IsAllergenCode(code string) bool {
+	for _, c := range AllergenCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// 🔥 КБЖУ
+type NutritionFacts struct {
+	Kcal    float64 `json:"kcal"`
+	Protein float64 `json:"protein"`
+	Fat     float64 `json:"fat"`
+	Carbs   float64 `json:"carbs"`
+}
+
+// 🍽 Пищевая ценность блюда, рассчитанная по калькуляции
+type DishNutrition struct {
+	PerPortion  NutritionFacts `json:"per_portion"`
+	Per100g     NutritionFacts `json:"per_100g"`
+	OutputGrams int            `json:"output_grams"`
+	Allergens   []string       `json:"allergens"`
+	Vegetarian  bool           `json:"vegetarian"`
+	Vegan       bool           `json:"vegan"`
+	Complete    bool           `json:"complete"`                      // все ингредиенты найдены на складе и проверены на аллергены
+	Missing     []string       `json:"missing_products,omitempty"`    // ингредиенты без данных
+	Unchecked   []string       `json:"unchecked_allergens,omitempty"` // продукты, у которых аллергены не указаны
+}
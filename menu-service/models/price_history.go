@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Источники изменения цены или себестоимости
+const (
+	PriceSourceCreate      = "create"      // блюдо создано
+	PriceSourceManual      = "manual"      // правка блюда через API
+	PriceSourceCalculation = "calculation" // пересчёт себестоимости по техкарте и ценам склада
+	PriceSourceImport      = "import"      // импорт блюд из файла
+	PriceSourceSnapshot    = "snapshot"    // публикация или откат версии меню
+	PriceSourceScheduled   = "scheduled"   // плановое изменение цены
+)
+
+// Статусы планового изменения цены
+const (
+	ScheduledPricePending   = "pending"
+	ScheduledPriceApplied   = "applied"
+	ScheduledPriceCancelled = "cancelled"
+)
+
+// 📈 Запись истории цены и себестоимости блюда (для аудита и графиков маржи)
+type PriceChange struct {
+	ID           string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID   string    `json:"menu_item_id" gorm:"type:uuid;not null;index:idx_price_change_item_time"`
+	OldPrice     Money     `json:"old_price"`
+	Price        Money     `json:"price" gorm:"not null"`
+	OldCostPrice Money     `json:"old_cost_price"`
+	CostPrice    Money     `json:"cost_price" gorm:"not null"`
+	Margin       Money     `json:"margin" gorm:"not null"` // маржа после изменения
+	Source       string    `json:"source" gorm:"type:varchar(20);not null"`
+	AuthorID     string    `json:"author_id,omitempty" gorm:"type:text"` // пусто — системное изменение
+	ScheduleID   *string   `json:"schedule_id,omitempty" gorm:"type:uuid"`
+	Comment      string    `json:"comment,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index:idx_price_change_item_time"`
+}
+
+// ⏰ Плановое изменение цены блюда с датой вступления в силу
+type ScheduledPriceChange struct {
+	ID          string     `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID  string     `json:"menu_item_id" gorm:"type:uuid;not null;index"`
+	Price       Money      `json:"price" gorm:"not null"`
+	EffectiveAt time.Time  `json:"effective_at" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	AuthorID    string     `json:"author_id,omitempty" gorm:"type:text"`
+	Comment     string     `json:"comment,omitempty" gorm:"type:text"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CancelledBy string     `json:"cancelled_by,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// 📈 История цены блюда и ещё не применённые плановые изменения
+type PriceHistory struct {
+	MenuItemID string                 `json:"menu_item_id"`
+	Name       string                 `json:"name"`
+	Price      Money                  `json:"price"`
+	CostPrice  Money                  `json:"cost_price"`
+	Margin     Money                  `json:"margin"`
+	Changes    []PriceChange          `json:"changes"`
+	Scheduled  []ScheduledPriceChange `json:"scheduled"`
+}
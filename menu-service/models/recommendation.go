@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// 🤝 Правило «заказывают вместе»: в заказах с MenuItemID встречается и RelatedID.
+// Support — доля заказов с обоими блюдами, Confidence — доля заказов с MenuItemID, где есть RelatedID,
+// Lift — во сколько раз пара встречается чаще, чем при независимых заказах.
+type MenuItemAssociation struct {
+	MenuItemID string    `json:"menu_item_id" gorm:"type:uuid;primaryKey"`
+	RelatedID  string    `json:"related_id" gorm:"type:uuid;primaryKey;index"`
+	PairOrders int       `json:"pair_orders"`
+	Support    float64   `json:"support"`
+	Confidence float64   `json:"confidence"`
+	Lift       float64   `json:"lift"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// 💡 Рекомендованное блюдо с метриками правила
+type MenuRecommendation struct {
+	MenuItem
+	Support    float64 `json:"support"`
+	Confidence float64 `json:"confidence"`
+	Lift       float64 `json:"lift"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Статусы модерации отзыва
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// ⭐ Отзыв о блюде от покупателя с доставленным заказом
+type Review struct {
+	ID             string         `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID     string         `json:"menu_item_id" gorm:"type:uuid;not null;uniqueIndex:idx_review_user_item;index"`
+	UserID         string         `json:"user_id,omitempty" gorm:"type:text;not null;uniqueIndex:idx_review_user_item"`
+	OrderID        uint           `json:"order_id,omitempty" gorm:"not null;index"` // заказ, подтверждающий покупку — для связи с гостем
+	Rating         int            `json:"rating" gorm:"not null"`                   // 1–5
+	Text           string         `json:"text" gorm:"type:text"`
+	ImageURL       string         `json:"image_url" gorm:"type:text"`
+	ImageVariants  []MediaVariant `json:"image_variants" gorm:"type:jsonb;serializer:json"`
+	Status         string         `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	ModeratedBy    string         `json:"moderated_by,omitempty" gorm:"type:text"`
+	ModeratedAt    *time.Time     `json:"moderated_at,omitempty"`
+	ModerationNote string         `json:"moderation_note,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// ⭐ Средняя оценка блюда по одобренным отзывам
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// 🔎 Результат поиска по меню
+type MenuSearchResult struct {
+	MenuItemWithCategory
+	Rank                 float64 `json:"rank"`
+	Similarity           float64 `json:"similarity"`
+	NameHighlight        string  `json:"name_highlight"`        // безопасный HTML: текст экранирован, совпадения в <mark>
+	DescriptionHighlight string  `json:"description_highlight"` // то же для описания
+}
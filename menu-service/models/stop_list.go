@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Ручные режимы стоп-листа
+const (
+	StopOverrideNone      = ""          // решает автоматика
+	StopOverrideStop      = "stop"      // блюдо снято вручную
+	StopOverrideAvailable = "available" // блюдо доступно, даже если продуктов не хватает
+)
+
+// ⛔ Запись стоп-листа блюда.
+// AutoStopped выставляется автоматически, когда продукта из техкарты нет в наличии
+// или его не хватает на одну порцию. ManualOverride позволяет перекрыть автоматику.
+type StopListEntry struct {
+	MenuItemID      string    `json:"menu_item_id" gorm:"type:uuid;primaryKey"`
+	AutoStopped     bool      `json:"auto_stopped" gorm:"default:false"`
+	MissingProducts []string  `json:"missing_products" gorm:"type:jsonb;serializer:json"`
+	ManualOverride  string    `json:"manual_override" gorm:"type:varchar(20);default:''"`
+	Reason          string    `json:"reason" gorm:"type:text"`
+	UpdatedBy       string    `json:"updated_by" gorm:"type:text"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Name            string    `json:"name" gorm:"-"` // название блюда для списка
+}
+
+// Снято ли блюдо с продажи с учётом ручного режима
+func (e StopListEntry) Stopped() bool {
+	switch e.ManualOverride {
+	case StopOverrideStop:
+		return true
+	case StopOverrideAvailable:
+		return false
+	}
+	return e.AutoStopped
+}
@@ -0,0 +1,7 @@
+package models
+
+// 📄 Данные технологической карты для печати: блюдо с категорией и версия техкарты
+type TechCard struct {
+	Dish        MenuItemWithCategory
+	Calculation Calculation
+}
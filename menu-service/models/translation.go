@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// 🌐 Перевод блюда
+type MenuItemTranslation struct {
+	ID          string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	MenuItemID  string    `json:"menu_item_id" gorm:"type:uuid;not null;uniqueIndex:idx_menu_item_translation"`
+	Lang        string    `json:"lang" gorm:"type:varchar(10);not null;uniqueIndex:idx_menu_item_translation"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// 🌐 Перевод категории меню
+type CategoryTranslation struct {
+	ID         string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CategoryID string    `json:"category_id" gorm:"type:uuid;not null;uniqueIndex:idx_category_translation"`
+	Lang       string    `json:"lang" gorm:"type:varchar(10);not null;uniqueIndex:idx_category_translation"`
+	Name       string    `json:"name" gorm:"not null"`
+	Description string   `json:"description" gorm:"type:text"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// 🌐 Перевод складской категории (ключи из пакета emoji: "овощи", "сыры", ...)
+type InventoryCategoryTranslation struct {
+	ID        string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Category  string    `json:"category" gorm:"not null;uniqueIndex:idx_inventory_category_translation"`
+	Lang      string    `json:"lang" gorm:"type:varchar(10);not null;uniqueIndex:idx_inventory_category_translation"`
+	Name      string    `json:"name" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 📊 Полнота переводов по одному языку
+type TranslationCompleteness struct {
+	Lang                string   `json:"lang"`
+	MenuItemsTotal      int      `json:"menu_items_total"`
+	MenuItemsDone       int      `json:"menu_items_translated"`
+	CategoriesTotal     int      `json:"categories_total"`
+	CategoriesDone      int      `json:"categories_translated"`
+	InventoryTotal      int      `json:"inventory_categories_total"`
+	InventoryDone       int      `json:"inventory_categories_translated"`
+	Percent             float64  `json:"percent"`
+	MissingMenuItems    []string `json:"missing_menu_items"`
+	MissingCategories   []string `json:"missing_categories"`
+	MissingInventoryCat []string `json:"missing_inventory_categories"`
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"monolith/menu-service/handlers"
+	"monolith/menu-service/middleware"
 )
 
 func SetupCategoryRoutes(app *fiber.App) {
@@ -11,6 +12,12 @@ func SetupCategoryRoutes(app *fiber.App) {
 	// Получить все категории
 	api.Get("/", handlers.GetAllCategories)
 
+	// Дерево категорий с опубликованными блюдами для витрины
+	api.Get("/tree", middleware.PublicMenuCache(), handlers.GetCategoryTree)
+
+	// Массовое изменение порядка категорий
+	api.Put("/reorder", handlers.ReorderCategories)
+
 	// Создать категорию
 	api.Post("/", handlers.CreateCategory)
 
@@ -19,5 +26,21 @@ func SetupCategoryRoutes(app *fiber.App) {
 
 	// Удалить категорию по ID
 	api.Delete("/:id", handlers.DeleteCategory)
+
+	// Окна доступности категории (например, завтраки 08:00–11:00)
+	api.Get("/:id/availability", handlers.GetCategoryAvailability)
+	api.Post("/:id/availability", handlers.CreateCategoryAvailability)
+
+	// Плановое открытие и скрытие категории
+	api.Put("/:id/schedule", handlers.ScheduleCategoryPublishing)
+
+	// Изображение категории (multipart, поле image)
+	api.Post("/:id/image", handlers.UploadCategoryImage)
+	api.Delete("/:id/image", handlers.DeleteCategoryImage)
+
+	// Переводы категории
+	api.Get("/:id/translations", handlers.GetCategoryTranslations)
+	api.Put("/:id/translations/:lang", handlers.UpsertCategoryTranslation)
+	api.Delete("/:id/translations/:lang", handlers.DeleteCategoryTranslation)
 }
 
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/handlers"
+	"monolith/menu-service/middleware"
+)
+
+// Комбо и сет-меню из существующих блюд
+func SetupComboRoutes(app *fiber.App) {
+	api := app.Group("/api/combos")
+
+	// Витрина (кэш с ETag): ?location=<id|code>
+	api.Get("/", middleware.PublicMenuCache(), handlers.GetCombos)
+	api.Get("/:id", handlers.GetComboByID)
+
+	// Администрирование
+	api.Post("/", handlers.CreateCombo)
+	api.Put("/:id", handlers.UpdateCombo)
+	api.Post("/:id/publish", handlers.PublishCombo)
+	api.Delete("/:id", handlers.DeleteCombo)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/handlers"
+)
+
+// Точки сети: меню и цены точки, склад, сотрудники, отчёт по продажам
+func SetupLocationRoutes(app *fiber.App) {
+	api := app.Group("/api/locations")
+
+	// Точки текущего пользователя и сводный отчёт (до /:id)
+	api.Get("/mine", handlers.GetMyLocations)
+	api.Get("/report", handlers.GetLocationSalesReport)
+
+	// Точки (:id — ID или код точки)
+	api.Get("/", handlers.GetLocations)
+	api.Post("/", handlers.CreateLocation)
+	api.Get("/:id", handlers.GetLocation)
+	api.Put("/:id", handlers.UpdateLocation)
+	api.Delete("/:id", handlers.DeleteLocation)
+	api.Post("/:id/restore", handlers.RestoreLocation)
+
+	// Цены и доступность блюд в точке
+	api.Get("/:id/menu", handlers.GetLocationMenu)
+	api.Put("/:id/menu/:menuItemId", handlers.SetLocationMenuItem)
+	api.Delete("/:id/menu/:menuItemId", handlers.DeleteLocationMenuItem)
+
+	// Остатки склада точки
+	api.Get("/:id/stock", handlers.GetLocationStock)
+	api.Put("/:id/stock/:inventoryItemId", handlers.SetLocationStock)
+	api.Delete("/:id/stock/:inventoryItemId", handlers.DeleteLocationStock)
+
+	// Сотрудники и их роли в точке
+	api.Get("/:id/staff", handlers.GetLocationStaff)
+	api.Put("/:id/staff/:userId", handlers.AssignLocationStaff)
+	api.Delete("/:id/staff/:userId", handlers.RemoveLocationStaff)
+}
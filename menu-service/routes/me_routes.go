@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"monolith/menu-service/handlers"
+)
+
+// Личные маршруты гостя (пользователь берётся из токена)
+func SetupMeRoutes(app *fiber.App) {
+	me := app.Group("/api/me")
+
+	// Избранное
+	me.Get("/favorites", handlers.GetMyFavorites)
+	me.Put("/favorites/:menuItemId", handlers.AddFavorite)
+	me.Delete("/favorites/:menuItemId", handlers.RemoveFavorite)
+
+	// Повтор заказа в текущую корзину
+	me.Post("/orders/:id/reorder", handlers.ReorderMyOrder)
+}
@@ -3,6 +3,7 @@ package routes
 import (
     "github.com/gofiber/fiber/v2"
     "monolith/menu-service/handlers"
+    "monolith/menu-service/middleware"
 )
 
 // Настройка основных маршрутов меню и склада
@@ -11,28 +12,127 @@ func SetupMenuRoutes(app *fiber.App) {
 
     // Склад
     menu.Get("/inventory", handlers.GetInventoryItems)
+    menu.Get("/inventory/classification", handlers.GetInventoryClassification)
     menu.Post("/inventory", handlers.CreateInventoryItem)
     menu.Put("/inventory/:id", handlers.UpdateInventoryItem)
     menu.Delete("/inventory/:id", handlers.DeleteInventoryItem)
+    menu.Get("/inventory-categories/translations", handlers.GetInventoryCategoryTranslations)
+    menu.Put("/inventory-categories/:category/translations/:lang", handlers.UpsertInventoryCategoryTranslation)
+
+    // Импорт и экспорт: categories, dishes, inventory, calculations (CSV, XLSX, JSON)
+    menu.Post("/import/:entity", handlers.ImportMenuData)
+    menu.Get("/export/:entity", handlers.ExportMenuData)
 
     // Калькуляция блюда
+    menu.Post("/calculation/preview", handlers.PreviewCalculation)
     menu.Get("/calculation/:menuItemId", handlers.GetCalculationByMenuItemID)
     menu.Post("/calculation", handlers.CreateCalculationForDish)
-
-    // Публичное меню
-    menu.Get("/published", handlers.GetPublishedMenuItems)
-    menu.Get("/published-with-category", handlers.GetPublishedMenuItemsWithCategory)
+    menu.Get("/calculation/:menuItemId/versions", handlers.GetCalculationVersions)
+    menu.Get("/calculation/:menuItemId/versions/:version", handlers.GetCalculationVersion)
+    menu.Get("/calculation/:menuItemId/diff", handlers.DiffCalculationVersions)
+    menu.Post("/calculation/:menuItemId/rollback/:version", handlers.RollbackCalculation)
+
+    // Технологические карты для печати (PDF): блюдо или вся категория
+    menu.Get("/calculation/:menuItemId/tech-card", handlers.GetTechCardPDF)
+    menu.Get("/tech-cards", handlers.GetCategoryTechCardsPDF)
+
+    // Публичное меню (кэш с ETag, сбрасывается при изменении меню)
+    menu.Get("/search", middleware.PublicMenuCache(), handlers.SearchMenuItems)
+    menu.Get("/allergens", handlers.GetAllergenCodes)
+    menu.Get("/published", middleware.PublicMenuCache(), handlers.GetPublishedMenuItems)
+    menu.Get("/published-with-category", middleware.PublicMenuCache(), handlers.GetPublishedMenuItemsWithCategory)
+
+    // Меню-инжиниринг (матрица Kasavana–Smith, фудкост, выгрузка CSV/XLSX)
+    menu.Get("/reports/engineering", handlers.GetMenuEngineeringReport)
+
+    // Черновики и опубликованные версии меню
+    menu.Get("/drafts", handlers.GetMenuDrafts)
+    menu.Post("/drafts", handlers.CreateMenuDraft)
+    menu.Get("/drafts/:draftId", handlers.GetMenuDraft)
+    menu.Put("/drafts/:draftId", handlers.UpdateMenuDraft)
+    menu.Delete("/drafts/:draftId", handlers.DiscardMenuDraft)
+    menu.Post("/drafts/:draftId/items", handlers.UpsertDraftItem)
+    menu.Put("/drafts/:draftId/items/:itemId", handlers.UpsertDraftItem)
+    menu.Delete("/drafts/:draftId/items/:itemId", handlers.RemoveDraftItem)
+    menu.Post("/drafts/:draftId/categories", handlers.UpsertDraftCategory)
+    menu.Put("/drafts/:draftId/categories/:categoryId", handlers.UpsertDraftCategory)
+    menu.Delete("/drafts/:draftId/categories/:categoryId", handlers.RemoveDraftCategory)
+    menu.Get("/drafts/:draftId/diff", handlers.DiffMenuDraft)
+    menu.Post("/drafts/:draftId/publish", handlers.PublishMenuDraft)
+    menu.Get("/snapshots", handlers.GetMenuSnapshots)
+    menu.Get("/snapshots/diff", handlers.DiffMenuSnapshots)
+    menu.Get("/snapshots/:version", handlers.GetMenuSnapshot)
+    menu.Post("/snapshots/:version/rollback", handlers.RollbackMenuSnapshot)
+
+    // Отзывы: модерация и фото (до /:id)
+    menu.Get("/reviews", handlers.GetReviewsForModeration)
+    menu.Get("/reviews/:reviewId", handlers.GetReviewWithOrder)
+    menu.Put("/reviews/:reviewId/moderation", handlers.ModerateReview)
+    menu.Delete("/reviews/:reviewId", handlers.DeleteReview)
+    menu.Post("/reviews/:reviewId/photo", handlers.UploadReviewPhoto)
+    menu.Delete("/reviews/:reviewId/photo", handlers.DeleteReviewPhoto)
+
+    // Стоп-лист (до /:id)
+    menu.Get("/stop-list", handlers.GetStopList)
+    menu.Post("/stop-list/refresh", handlers.RefreshStopList)
+
+    // Плановые изменения цены всех блюд (до /:id)
+    menu.Get("/price-schedule", handlers.GetScheduledPriceChanges)
+    menu.Delete("/price-schedule/:scheduleId", handlers.CancelScheduledPriceChange)
 
     // Администрирование меню
     menu.Get("/with-category", handlers.GetAllMenuItemsWithCategory)
     menu.Get("/", handlers.GetAllMenuItems)
     menu.Get("/:id", handlers.GetMenuItemByID)
     menu.Post("/", handlers.CreateMenuItem)
+    menu.Put("/reorder", handlers.ReorderMenuItems)
     menu.Put("/:id", handlers.UpdateMenuItem)
     menu.Delete("/:id", handlers.DeleteMenuItem)
 
+    // Себестоимость по калькуляции и текущим ценам склада
+    menu.Post("/recalculate-costs", handlers.RecalculateAllCosts)
+    menu.Post("/:id/recalculate-cost", handlers.RecalculateMenuItemCost)
+
     // Публикация / снятие публикации
     menu.Post("/:id/publish", handlers.PublishMenuItem)
+
+    // Ручная отметка блюда в стоп-листе
+    menu.Put("/:id/stop-list", handlers.SetStopListOverride)
+
+    // Окна доступности и плановая публикация
+    menu.Get("/:id/availability", handlers.GetMenuItemAvailability)
+    menu.Post("/:id/availability", handlers.CreateMenuItemAvailability)
+    menu.Delete("/availability/:windowId", handlers.DeleteAvailabilityWindow)
+    menu.Put("/:id/schedule", handlers.ScheduleMenuItemPublishing)
+
+    // История цен и плановые изменения цены
+    menu.Get("/:id/price-history", handlers.GetMenuItemPriceHistory)
+    menu.Post("/:id/price-schedule", handlers.SchedulePriceChange)
+
+    // Переводы
+    menu.Get("/translations/completeness", handlers.GetTranslationCompleteness)
+    menu.Get("/:id/translations", handlers.GetMenuItemTranslations)
+    menu.Put("/:id/translations/:lang", handlers.UpsertMenuItemTranslation)
+    menu.Delete("/:id/translations/:lang", handlers.DeleteMenuItemTranslation)
+
+    // Изображение блюда (multipart, поле image) → WebP-варианты
+    menu.Post("/:id/image", handlers.UploadMenuItemImage)
+    menu.Delete("/:id/image", handlers.DeleteMenuItemImage)
+
+    // Отзывы о блюде: одобренные — всем, оставить — после доставки заказа с блюдом
+    menu.Get("/:id/reviews", handlers.GetMenuItemReviews)
+    menu.Post("/:id/reviews", handlers.CreateMenuItemReview)
+
+    // «Часто заказывают вместе» (правила пересчитываются фоновой задачей)
+    menu.Get("/:id/recommendations", middleware.PublicMenuCache(), handlers.GetMenuItemRecommendations)
+    menu.Post("/recommendations/recompute", handlers.RecomputeRecommendations)
+
+    // Модификаторы блюда (размеры, обязательный выбор, добавки)
+    menu.Get("/:id/nutrition", handlers.GetMenuItemNutrition)
+    menu.Get("/:id/modifiers", handlers.GetModifierGroups)
+    menu.Post("/:id/modifiers", handlers.CreateModifierGroup)
+    menu.Put("/modifiers/:groupId", handlers.UpdateModifierGroup)
+    menu.Delete("/modifiers/:groupId", handlers.DeleteModifierGroup)
 }
 
 // Маршруты корзины
@@ -40,6 +140,7 @@ func SetupMenuRoutes(app *fiber.App) {
 func SetupCartRoutes(app *fiber.App) {
     cart := app.Group("/api/users/:userId/cart")
     cart.Get("/",    handlers.GetCart)
+    cart.Get("/suggestions", handlers.GetCartSuggestions)
     cart.Post("/",   handlers.AddToCart)
     cart.Put("/:menuItemId", handlers.UpdateCartItem)
     cart.Delete("/:menuItemId", handlers.RemoveCartItem)
@@ -54,6 +155,9 @@ func SetupOrderRoutes(app *fiber.App) {
     order := app.Group("/api/users/:userId")
     order.Post("/order", handlers.PlaceOrder)      // ← PlaceOrder, а не PlaceOrderHandler
     order.Get("/orders", handlers.GetUserOrders)   // ← GetUserOrders, а не GetUserOrdersHandler
+
+    // Статус заказа (кухня, доставка)
+    app.Put("/api/orders/:id/status", handlers.UpdateOrderStatus)
 }
 
 
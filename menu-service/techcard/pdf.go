@@ -0,0 +1,224 @@
+package techcard
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+const (
+	fontFamily = "Go" // шрифты Go встроены в бинарник и содержат кириллицу
+	margin     = 15.0
+	lineHeight = 5.0
+)
+
+// Колонки таблицы ингредиентов: ширина в мм и выравнивание
+var columns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"№", 8, "C"},
+	{"Продукт", 62, "L"},
+	{"Брутто, г", 20, "R"},
+	{"Отходы, %", 18, "R"},
+	{"Нетто, г", 20, "R"},
+	{"Цена за кг", 26, "R"},
+	{"Сумма", 26, "R"},
+}
+
+// 📄 Записать технологические карты в один PDF: каждая карта с новой страницы
+func Write(w io.Writer, title string, cards []models.TechCard) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("menu-service", true)
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin+5)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.AliasNbPages("{nb}")
+
+	printed := utils.RestaurantNow().Format("02.01.2006 15:04")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.CellFormat(90, 4, "Сформировано "+printed, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 4, fmt.Sprintf("Стр. %d из {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	for _, card := range cards {
+		pdf.AddPage()
+		writeCard(pdf, card)
+	}
+	return pdf.Output(w)
+}
+
+func writeCard(pdf *gofpdf.Fpdf, card models.TechCard) {
+	calc := card.Calculation
+
+	// Шапка: предприятие слева, гриф утверждения справа
+	pdf.SetFont(fontFamily, "", 9)
+	top := pdf.GetY()
+	// Название предприятия (RESTAURANT_NAME) читается при печати — уже после загрузки .env
+	if organization := os.Getenv("RESTAURANT_NAME"); organization != "" {
+		pdf.MultiCell(90, 4.5, organization, "", "L", false)
+	}
+	pdf.SetXY(120, top)
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.CellFormat(75, 4.5, "УТВЕРЖДАЮ", "", 2, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(75, 4.5, "Руководитель предприятия", "", 2, "L", false, 0, "")
+	pdf.CellFormat(75, 6, "____________ / ________________ /", "", 2, "L", false, 0, "")
+	pdf.CellFormat(75, 4.5, "«___» ______________ 20___ г.", "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont(fontFamily, "B", 14)
+	pdf.CellFormat(0, 7, "ТЕХНОЛОГИЧЕСКАЯ КАРТА", "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.MultiCell(0, 6, card.Dish.Name, "", "C", false)
+	pdf.SetFont(fontFamily, "", 9)
+	subtitle := fmt.Sprintf("Версия техкарты %d, действует с %s", calc.Version, calc.EffectiveFrom.Format("02.01.2006"))
+	if card.Dish.CategoryName != "" {
+		subtitle = "Категория: " + card.Dish.CategoryName + " · " + subtitle
+	}
+	pdf.CellFormat(0, 5, subtitle, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	writeIngredients(pdf, calc)
+	pdf.Ln(3)
+
+	pdf.SetFont(fontFamily, "", 10)
+	output := fmt.Sprintf("Выход готового блюда: %d г", calc.TotalOutputGrams)
+	if calc.YieldPercent > 0 {
+		output += fmt.Sprintf(" (%s%% от веса нетто)", number(calc.YieldPercent))
+	}
+	pdf.CellFormat(0, 6, output, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Себестоимость порции: "+calc.TotalCost.String()+" руб.", "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(0, 6, "Технология приготовления, оформление и подача", "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	notes := strings.TrimSpace(calc.CookingNotes)
+	if notes == "" {
+		notes = "Не указана."
+	}
+	pdf.MultiCell(0, lineHeight, notes, "", "L", false)
+	pdf.Ln(8)
+
+	writeSignatures(pdf)
+}
+
+// Таблица ингредиентов с итогами; на новой странице заголовок таблицы повторяется
+func writeIngredients(pdf *gofpdf.Fpdf, calc models.Calculation) {
+	header := func() {
+		pdf.SetFont(fontFamily, "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		titles := make([]string, len(columns))
+		for i, col := range columns {
+			titles[i] = col.title
+		}
+		tableRow(pdf, titles, true)
+		pdf.SetFont(fontFamily, "", 9)
+	}
+	header()
+
+	var net float64
+	for i, ing := range calc.Ingredients {
+		net += ing.NetGrams
+		values := []string{
+			strconv.Itoa(i + 1),
+			ing.ProductName,
+			strconv.Itoa(ing.AmountGrams),
+			number(ing.WastePercent),
+			number(ing.NetGrams),
+			ing.PricePerKg.String(),
+			ing.TotalCost.String(),
+		}
+		if pdf.GetY()+rowHeight(pdf, values) > pageBottom(pdf) {
+			pdf.AddPage()
+			header()
+		}
+		tableRow(pdf, values, false)
+	}
+
+	pdf.SetFont(fontFamily, "B", 9)
+	if calc.TotalNetGrams > 0 {
+		net = calc.TotalNetGrams
+	}
+	tableRow(pdf, []string{
+		"", "Итого",
+		strconv.Itoa(calc.TotalGrossGrams),
+		"",
+		number(net),
+		"",
+		calc.TotalCost.String(),
+	}, false)
+	pdf.SetFont(fontFamily, "", 9)
+}
+
+// Высота строки таблицы: по самой длинной ячейке с переносом
+func rowHeight(pdf *gofpdf.Fpdf, values []string) float64 {
+	lines := 1
+	for i, v := range values {
+		if n := len(pdf.SplitText(v, columns[i].width-2)); n > lines {
+			lines = n
+		}
+	}
+	return float64(lines) * lineHeight
+}
+
+func tableRow(pdf *gofpdf.Fpdf, values []string, fill bool) {
+	height := rowHeight(pdf, values)
+	x, y := pdf.GetX(), pdf.GetY()
+	for i, v := range values {
+		col := columns[i]
+		style := "D"
+		if fill {
+			style = "FD"
+		}
+		pdf.Rect(x, y, col.width, height, style)
+		pdf.SetXY(x, y)
+		pdf.MultiCell(col.width, lineHeight, v, "", col.align, false)
+		x += col.width
+	}
+	pdf.SetXY(margin, y+height)
+}
+
+func pageBottom(pdf *gofpdf.Fpdf) float64 {
+	_, pageHeight := pdf.GetPageSize()
+	_, bottom := pdf.GetAutoPageBreak()
+	return pageHeight - bottom
+}
+
+// Подписи ответственных; блок не разрывается между страницами
+func writeSignatures(pdf *gofpdf.Fpdf) {
+	signers := []string{
+		"Технолог (составил)",
+		"Шеф-повар",
+		"Заведующий производством",
+	}
+	if pdf.GetY()+float64(len(signers))*9 > pageBottom(pdf) {
+		pdf.AddPage()
+	}
+	pdf.SetFont(fontFamily, "", 10)
+	for _, signer := range signers {
+		pdf.CellFormat(70, 9, signer, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 9, "____________ / ________________ /", "", 1, "L", false, 0, "")
+	}
+}
+
+// Число без лишних нулей: 12 или 12.5
+func number(v float64) string {
+	v = math.Round(v*10) / 10
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,209 @@
+package transfer
+
+import (
+	"errors"
+	"strconv"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Техкарты: строка на ингредиент, строки группируются по блюду.
+// Каждое изменённое блюдо получает новую версию техкарты; совпадающие с действующей — пропускаются.
+var calculationColumns = []column{
+	{name: "dish", required: true},
+	{name: "product_name", required: true},
+	{name: "amount_grams", kind: kindNumber, required: true},
+	{name: "waste_percent", kind: kindNumber},
+	{name: "output_grams", kind: kindNumber},
+	{name: "comment"},
+}
+
+// Строки одного блюда в порядке файла
+type calculationGroup struct {
+	dish string
+	rows []Row
+}
+
+func importCalculations(tx *gorm.DB, rows []Row, opts ImportOptions, report *Report) ([]string, error) {
+	var groups []*calculationGroup
+	byDish := map[string]*calculationGroup{}
+	for _, row := range rows {
+		dish := row.Get("dish")
+		if dish == "" {
+			report.fail(row.Line, "dish", "название блюда обязательно")
+			continue
+		}
+		g, ok := byDish[naturalKey(dish)]
+		if !ok {
+			g = &calculationGroup{dish: dish}
+			byDish[naturalKey(dish)] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	var ids []string
+	for _, g := range groups {
+		first := g.rows[0]
+		item, ok, err := findDishByName(tx, report, first, "dish", g.dish)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if item == nil {
+			report.fail(first.Line, "dish", "блюдо %q не найдено — сначала импортируйте блюда", g.dish)
+			continue
+		}
+
+		calc, ok := buildCalculation(report, g)
+		if !ok {
+			continue
+		}
+		calc.MenuItemID = item.ID
+		calc.AuthorID = opts.AuthorID
+		if err := utils.ComputeCalculation(tx, calc); err != nil {
+			var vErr *utils.ValidationError
+			if errors.As(err, &vErr) {
+				report.fail(first.Line, "", "%s: %s", g.dish, vErr.Message)
+				continue
+			}
+			return nil, err
+		}
+
+		active, err := utils.GetActiveCalculations(tx, []string{item.ID})
+		if err != nil {
+			return nil, err
+		}
+		action := ActionCreate
+		if current, exists := active[item.ID]; exists {
+			// В файле нет технологии приготовления — она переходит из действующей версии
+			calc.CookingNotes = current.CookingNotes
+			if sameCalculation(current, *calc) {
+				report.add(first.Line, g.dish, ActionUnchanged)
+				continue
+			}
+			action = ActionUpdate
+		}
+		if err := utils.SaveDishCalculation(tx, calc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, item.ID)
+		report.add(first.Line, g.dish, action)
+	}
+	return ids, nil
+}
+
+// Собрать техкарту из строк блюда; выход и комментарий берутся из первой строки, где заданы
+func buildCalculation(report *Report, g *calculationGroup) (*models.Calculation, bool) {
+	calc := &models.Calculation{Comment: "Импорт"}
+	errCount := len(report.Errors)
+	seen := keySeen{}
+	for _, row := range g.rows {
+		product := row.Get("product_name")
+		if product == "" {
+			report.fail(row.Line, "product_name", "продукт обязателен")
+			continue
+		}
+		if !seen.check(report, row, "product_name", product) {
+			continue
+		}
+		amount, ok1 := cellInt(report, row, "amount_grams", 1)
+		waste, ok2 := cellFloat(report, row, "waste_percent", 0, 99.9)
+		output, ok3 := cellInt(report, row, "output_grams", 0)
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+		if row.Get("amount_grams") == "" {
+			report.fail(row.Line, "amount_grams", "вес брутто обязателен")
+			continue
+		}
+		if output > 0 && calc.TotalOutputGrams == 0 {
+			calc.TotalOutputGrams = output
+		} else if output > 0 && output != calc.TotalOutputGrams {
+			report.fail(row.Line, "output_grams", "выход блюда %q отличается от указанного выше (%d г)", g.dish, calc.TotalOutputGrams)
+			continue
+		}
+		if comment := row.Get("comment"); comment != "" && calc.Comment == "Импорт" {
+			calc.Comment = comment
+		}
+		calc.Ingredients = append(calc.Ingredients, models.CalculationIngredient{
+			ProductName:  product,
+			AmountGrams:  amount,
+			WastePercent: waste,
+		})
+	}
+	return calc, len(report.Errors) == errCount
+}
+
+// Совпадает ли новая техкарта с действующей: те же продукты, веса, отходы и выход
+func sameCalculation(current, next models.Calculation) bool {
+	if current.TotalOutputGrams != next.TotalOutputGrams || len(current.Ingredients) != len(next.Ingredients) {
+		return false
+	}
+	type key struct {
+		grams int
+		waste float64
+	}
+	existing := make(map[string]key, len(current.Ingredients))
+	for _, ing := range current.Ingredients {
+		existing[ingredientKey(ing)] = key{ing.AmountGrams, ing.WastePercent}
+	}
+	for _, ing := range next.Ingredients {
+		if k, ok := existing[ingredientKey(ing)]; !ok || k != (key{ing.AmountGrams, ing.WastePercent}) {
+			return false
+		}
+	}
+	return true
+}
+
+func ingredientKey(ing models.CalculationIngredient) string {
+	if ing.InventoryItemID != nil {
+		return *ing.InventoryItemID
+	}
+	return naturalKey(ing.ProductName)
+}
+
+func exportCalculations(db *gorm.DB, filter ExportFilter) ([][]string, error) {
+	var items []models.MenuItem
+	q := db.Model(&models.MenuItem{})
+	if filter.CategoryID != "" {
+		q = q.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.Published != nil {
+		q = q.Where("published = ?", *filter.Published)
+	}
+	if err := q.Order("sort_order, name").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	calcs, err := utils.GetActiveCalculations(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{}
+	for _, item := range items {
+		calc, ok := calcs[item.ID]
+		if !ok {
+			continue
+		}
+		for _, ing := range calc.Ingredients {
+			records = append(records, []string{
+				item.Name,
+				ing.ProductName,
+				strconv.Itoa(ing.AmountGrams),
+				formatFloat(ing.WastePercent),
+				strconv.Itoa(calc.TotalOutputGrams),
+				calc.Comment,
+			})
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,182 @@
+package transfer
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Категории меню: ключ — название, родитель указывается по названию
+var categoryColumns = []column{
+	{name: "name", required: true},
+	{name: "parent"},
+	{name: "sort_order", kind: kindNumber},
+	{name: "description"},
+	{name: "hidden", kind: kindBool},
+	{name: "image_url"},
+}
+
+// Найти категорию по названию без учёта регистра
+func findCategoryByName(tx *gorm.DB, name string) (*models.Category, error) {
+	var category models.Category
+	err := tx.Where("LOWER(name) = ?", naturalKey(name)).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Два прохода: сначала создаём/обновляем категории, затем расставляем родителей —
+// так родитель может идти в файле после своих подкатегорий. Итог строки известен
+// после обоих проходов: категория без изменений может сменить только родителя.
+func importCategories(tx *gorm.DB, rows []Row, _ ImportOptions, report *Report) ([]string, error) {
+	seen := keySeen{}
+	saved := map[int]*models.Category{} // строка → категория
+	actions := map[int]string{}
+	var lines []int
+
+	for _, row := range rows {
+		name := row.Get("name")
+		if name == "" {
+			report.fail(row.Line, "name", "название обязательно")
+			continue
+		}
+		if !seen.check(report, row, "name", name) {
+			continue
+		}
+		sortOrder, ok1 := cellInt(report, row, "sort_order", 0)
+		hidden, ok2 := cellBool(report, row, "hidden", false)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		category, err := findCategoryByName(tx, name)
+		if err != nil {
+			return nil, err
+		}
+		action := ActionUpdate
+		if category == nil {
+			action = ActionCreate
+			category = &models.Category{}
+		}
+		before := *category
+		category.Name = name
+		if row.Filled("sort_order") {
+			category.SortOrder = sortOrder
+		}
+		if row.Has("description") {
+			category.Description = row.Get("description")
+		}
+		if row.Filled("hidden") {
+			category.Hidden = hidden
+		}
+		if row.Has("image_url") {
+			category.ImageURL = row.Get("image_url")
+		}
+		if action == ActionUpdate && reflect.DeepEqual(before, *category) {
+			action = ActionUnchanged
+		} else if err := tx.Save(category).Error; err != nil {
+			return nil, err
+		}
+		saved[row.Line] = category
+		actions[row.Line] = action
+		lines = append(lines, row.Line)
+	}
+
+	if rows[0].Has("parent") {
+		for _, row := range rows {
+			category, ok := saved[row.Line]
+			if !ok {
+				continue
+			}
+			var parentID *string
+			if parentName := row.Get("parent"); parentName != "" {
+				parent, err := findCategoryByName(tx, parentName)
+				if err != nil {
+					return nil, err
+				}
+				if parent == nil {
+					report.fail(row.Line, "parent", "родительская категория %q не найдена", parentName)
+					continue
+				}
+				if err := utils.ValidateCategoryParent(tx, category.ID, &parent.ID); err != nil {
+					var vErr *utils.ValidationError
+					if errors.As(err, &vErr) {
+						report.fail(row.Line, "parent", "%s", vErr.Message)
+						continue
+					}
+					return nil, err
+				}
+				parentID = &parent.ID
+			}
+			if sameParent(category.ParentID, parentID) {
+				continue
+			}
+			if err := tx.Model(category).Update("parent_id", parentID).Error; err != nil {
+				return nil, err
+			}
+			if actions[row.Line] == ActionUnchanged {
+				actions[row.Line] = ActionUpdate
+			}
+		}
+	}
+
+	for _, line := range lines {
+		report.add(line, saved[line].Name, actions[line])
+	}
+	return nil, nil
+}
+
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Фильтры выгрузки (пустые поля не фильтруют)
+type ExportFilter struct {
+	CategoryID        string // блюда и техкарты одной категории
+	Published         *bool  // блюда: опубликованные или нет
+	Hidden            *bool  // категории: скрытые или видимые
+	Available         *bool  // склад: в наличии или нет
+	InventoryCategory string // склад: складская категория ("овощи", "сыры", ...)
+}
+
+func exportCategories(db *gorm.DB, filter ExportFilter) ([][]string, error) {
+	var categories []models.Category
+	if err := db.Order("sort_order, name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+
+	records := [][]string{}
+	for _, c := range categories {
+		if filter.Hidden != nil && c.Hidden != *filter.Hidden {
+			continue
+		}
+		parent := ""
+		if c.ParentID != nil {
+			parent = names[*c.ParentID]
+		}
+		records = append(records, []string{
+			c.Name,
+			parent,
+			strconv.Itoa(c.SortOrder),
+			c.Description,
+			strconv.FormatBool(c.Hidden),
+			c.ImageURL,
+		})
+	}
+	return records, nil
+}
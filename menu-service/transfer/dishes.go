@@ -0,0 +1,174 @@
+package transfer
+
+import (
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// Блюда: ключ — название, категория указывается по названию.
+// Себестоимость блюд с техкартой после импорта пересчитывается по калькуляции.
+var dishColumns = []column{
+	{name: "name", required: true},
+	{name: "category"},
+	{name: "description"},
+	{name: "price", kind: kindNumber},
+	{name: "cost_price", kind: kindNumber},
+	{name: "currency"},
+	{name: "image_url"},
+	{name: "published", kind: kindBool},
+	{name: "sort_order", kind: kindNumber},
+}
+
+// Найти блюдо по названию; несколько блюд с одним названием — ошибка строки
+func findDishByName(tx *gorm.DB, report *Report, row Row, column, name string) (*models.MenuItem, bool, error) {
+	var items []models.MenuItem
+	if err := tx.Where("LOWER(name) = ?", naturalKey(name)).Limit(2).Find(&items).Error; err != nil {
+		return nil, false, err
+	}
+	switch len(items) {
+	case 0:
+		return nil, true, nil
+	case 1:
+		return &items[0], true, nil
+	default:
+		report.fail(row.Line, column, "несколько блюд с названием %q — переименуйте их, чтобы импорт был однозначным", name)
+		return nil, false, nil
+	}
+}
+
+func importDishes(tx *gorm.DB, rows []Row, opts ImportOptions, report *Report) ([]string, error) {
+	seen := keySeen{}
+	var ids []string
+
+	for _, row := range rows {
+		name := row.Get("name")
+		if name == "" {
+			report.fail(row.Line, "name", "название обязательно")
+			continue
+		}
+		if !seen.check(report, row, "name", name) {
+			continue
+		}
+		errCount := len(report.Errors)
+		price, _ := cellMoney(report, row, "price")
+		costPrice, _ := cellMoney(report, row, "cost_price")
+		currency, _ := cellCurrency(report, row, "currency")
+		published, _ := cellBool(report, row, "published", false)
+		sortOrder, _ := cellInt(report, row, "sort_order", 0)
+
+		var categoryID string
+		if categoryName := row.Get("category"); categoryName != "" {
+			category, err := findCategoryByName(tx, categoryName)
+			if err != nil {
+				return nil, err
+			}
+			if category == nil {
+				report.fail(row.Line, "category", "категория %q не найдена — сначала импортируйте категории", categoryName)
+			} else {
+				categoryID = category.ID
+			}
+		}
+
+		item, ok, err := findDishByName(tx, report, row, "name", name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		action := ActionUpdate
+		if item == nil {
+			action = ActionCreate
+			item = &models.MenuItem{Currency: models.DefaultCurrency}
+			if row.Get("price") == "" {
+				report.fail(row.Line, "price", "цена обязательна для нового блюда")
+			}
+		}
+		if len(report.Errors) > errCount {
+			continue
+		}
+
+		before := *item
+		item.Name = name
+		if row.Has("category") {
+			item.CategoryID = categoryID
+		}
+		if row.Has("description") {
+			item.Description = row.Get("description")
+		}
+		if row.Filled("price") {
+			item.Price = price
+		}
+		if row.Filled("cost_price") {
+			item.CostPrice = costPrice
+		}
+		if row.Filled("currency") {
+			item.Currency = currency
+		}
+		if row.Has("image_url") {
+			item.ImageURL = row.Get("image_url")
+		}
+		if row.Filled("published") {
+			item.Published = published
+		}
+		if row.Filled("sort_order") {
+			item.SortOrder = sortOrder
+		}
+		item.Margin = item.Price - item.CostPrice
+		if action == ActionUpdate && reflect.DeepEqual(before, *item) {
+			report.add(row.Line, name, ActionUnchanged)
+			continue
+		}
+
+		if err := tx.Save(item).Error; err != nil {
+			return nil, err
+		}
+		source := models.PriceSourceImport
+		if action == ActionCreate {
+			before.ID = item.ID
+			source = models.PriceSourceCreate
+		}
+		if err := utils.RecordMenuItemPriceChange(tx, before, *item, source, opts.AuthorID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, item.ID)
+		report.add(row.Line, name, action)
+	}
+	return ids, nil
+}
+
+func exportDishes(db *gorm.DB, filter ExportFilter) ([][]string, error) {
+	var items []models.MenuItemWithCategory
+	q := db.Table("menu_items").
+		Select("menu_items.*, categories.name as category_name").
+		Joins("LEFT JOIN categories ON menu_items.category_id = categories.id")
+	if filter.CategoryID != "" {
+		q = q.Where("menu_items.category_id = ?", filter.CategoryID)
+	}
+	if filter.Published != nil {
+		q = q.Where("menu_items.published = ?", *filter.Published)
+	}
+	if err := q.Order("categories.sort_order, categories.name, menu_items.sort_order, menu_items.name").Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(items))
+	for _, item := range items {
+		records = append(records, []string{
+			item.Name,
+			item.CategoryName,
+			item.Description,
+			item.Price.String(),
+			item.CostPrice.String(),
+			item.Currency,
+			item.ImageURL,
+			strconv.FormatBool(item.Published),
+			strconv.Itoa(item.SortOrder),
+		})
+	}
+	return records, nil
+}
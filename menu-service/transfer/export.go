@@ -0,0 +1,38 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// 📤 Выгрузить сущность в выбранном формате. Колонки совпадают с колонками импорта,
+// поэтому выгрузку одной точки можно загрузить в другую без правок.
+func Export(db *gorm.DB, w io.Writer, entity Entity, format Format, filter ExportFilter) error {
+	var (
+		columns []column
+		records [][]string
+		err     error
+	)
+	switch entity {
+	case EntityCategories:
+		columns = categoryColumns
+		records, err = exportCategories(db, filter)
+	case EntityDishes:
+		columns = dishColumns
+		records, err = exportDishes(db, filter)
+	case EntityInventory:
+		columns = inventoryColumns
+		records, err = exportInventory(db, filter)
+	case EntityCalculations:
+		columns = calculationColumns
+		records, err = exportCalculations(db, filter)
+	default:
+		return &FileError{Message: fmt.Sprintf("неизвестная сущность %q: допустимы categories, dishes, inventory, calculations", entity)}
+	}
+	if err != nil {
+		return err
+	}
+	return Encode(w, format, columns, records)
+}
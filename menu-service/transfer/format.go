@@ -0,0 +1,286 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 📄 Формат файла импорта/экспорта
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatJSON Format = "json"
+)
+
+// Разобрать формат из параметра запроса или расширения файла
+func ParseFormat(raw, filename string) (Format, error) {
+	if raw == "" {
+		raw = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+	switch f := Format(strings.ToLower(raw)); f {
+	case FormatCSV, FormatXLSX, FormatJSON:
+		return f, nil
+	case "":
+		return "", fmt.Errorf("укажите формат: csv, xlsx или json")
+	default:
+		return "", fmt.Errorf("неизвестный формат %q: допустимы csv, xlsx, json", raw)
+	}
+}
+
+// MIME-тип для ответа с выгрузкой
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Тип значения колонки — нужен для JSON-выгрузки и ячеек XLSX
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindNumber
+	kindBool
+	kindList // значения через запятую; в JSON — массив строк
+)
+
+type column struct {
+	name     string
+	kind     columnKind
+	required bool
+}
+
+// Строка входного файла: номер строки в файле и значения по именам колонок
+type Row struct {
+	Line   int
+	values map[string]string
+}
+
+// Есть ли колонка в файле — отсутствующие колонки при обновлении не трогают поле
+func (r Row) Has(name string) bool {
+	_, ok := r.values[name]
+	return ok
+}
+
+// Заполнена ли ячейка. Числа, деньги и флаги из пустой ячейки при обновлении не применяются:
+// пустая цена не должна обнулять цену блюда
+func (r Row) Filled(name string) bool {
+	return r.Get(name) != ""
+}
+
+func (r Row) Get(name string) string {
+	return strings.TrimSpace(r.values[name])
+}
+
+// Разобрать файл в строки. Заголовки колонок приводятся к нижнему регистру.
+func Decode(format Format, data []byte) ([]Row, error) {
+	switch format {
+	case FormatJSON:
+		return decodeJSON(data)
+	case FormatXLSX:
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("не удалось открыть XLSX: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("в файле нет листов")
+		}
+		records, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, err
+		}
+		return tableRows(records), nil
+	default:
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM из Excel
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		if semicolonSeparated(data) {
+			r.Comma = ';'
+		}
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("не удалось разобрать CSV: %w", err)
+		}
+		return tableRows(records), nil
+	}
+}
+
+// Excel в русской локали сохраняет CSV через точку с запятой
+func semicolonSeparated(data []byte) bool {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	return bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(","))
+}
+
+// Первая строка — заголовок; пустые строки пропускаются
+func tableRows(records [][]string) []Row {
+	if len(records) == 0 {
+		return nil
+	}
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	var rows []Row
+	for i, rec := range records[1:] {
+		values := make(map[string]string, len(header))
+		empty := true
+		for j, name := range header {
+			if name == "" {
+				continue
+			}
+			v := ""
+			if j < len(rec) {
+				v = rec[j]
+			}
+			if strings.TrimSpace(v) != "" {
+				empty = false
+			}
+			values[name] = v
+		}
+		if !empty {
+			rows = append(rows, Row{Line: i + 2, values: values})
+		}
+	}
+	return rows
+}
+
+// JSON — массив объектов; номер строки = позиция в массиве, начиная с 1
+func decodeJSON(data []byte) ([]Row, error) {
+	var objects []map[string]interface{}
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, fmt.Errorf("ожидается JSON-массив объектов: %w", err)
+	}
+	rows := make([]Row, 0, len(objects))
+	for i, obj := range objects {
+		values := make(map[string]string, len(obj))
+		for k, v := range obj {
+			s, err := jsonScalar(v)
+			if err != nil {
+				return nil, fmt.Errorf("элемент %d, поле %s: %w", i+1, k, err)
+			}
+			values[strings.ToLower(strings.TrimSpace(k))] = s
+		}
+		rows = append(rows, Row{Line: i + 1, values: values})
+	}
+	return rows, nil
+}
+
+func jsonScalar(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			s, err := jsonScalar(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("вложенные объекты не поддерживаются")
+	}
+}
+
+// Записать таблицу в выбранном формате
+func Encode(w io.Writer, format Format, columns []column, records [][]string) error {
+	switch format {
+	case FormatJSON:
+		objects := make([]map[string]interface{}, 0, len(records))
+		for _, rec := range records {
+			obj := make(map[string]interface{}, len(columns))
+			for i, col := range columns {
+				obj[col.name] = typedValue(col.kind, rec[i])
+			}
+			objects = append(objects, obj)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(objects)
+	case FormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+		sheet := f.GetSheetName(0)
+		for i, col := range columns {
+			cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+			f.SetCellValue(sheet, cell, col.name)
+		}
+		for r, rec := range records {
+			for i, col := range columns {
+				cell, _ := excelize.CoordinatesToCellName(i+1, r+2)
+				if col.kind == kindList {
+					f.SetCellValue(sheet, cell, rec[i])
+				} else {
+					f.SetCellValue(sheet, cell, typedValue(col.kind, rec[i]))
+				}
+			}
+		}
+		return f.Write(w)
+	default:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.name
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+}
+
+// Значение ячейки в исходном типе: числа — числами, флаги — булевыми
+func typedValue(kind columnKind, s string) interface{} {
+	switch kind {
+	case kindNumber:
+		if s == "" {
+			return nil
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	case kindBool:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	case kindList:
+		if s == "" {
+			return []string{}
+		}
+		return strings.Split(s, ",")
+	}
+	return s
+}
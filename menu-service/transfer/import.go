@@ -0,0 +1,303 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils"
+)
+
+// 📦 Сущности, которые можно загружать и выгружать
+type Entity string
+
+const (
+	EntityCategories   Entity = "categories"
+	EntityDishes       Entity = "dishes"
+	EntityInventory    Entity = "inventory"
+	EntityCalculations Entity = "calculations"
+)
+
+// Предельный размер файла импорта (учитывается и в лимите тела запроса приложения)
+const MaxImportBytes = 10 << 20
+
+// Действия над строкой импорта
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+// ❗ Ошибка в конкретной строке файла
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Результат по строке: какой ключ и что с ним будет сделано
+type RowResult struct {
+	Row    int    `json:"row"`
+	Key    string `json:"key"`
+	Action string `json:"action"`
+}
+
+// 📋 Отчёт об импорте (и предпросмотр, и применение)
+type Report struct {
+	Entity    Entity      `json:"entity"`
+	DryRun    bool        `json:"dry_run"`
+	Applied   bool        `json:"applied"`
+	Total     int         `json:"total"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Rows      []RowResult `json:"rows"`
+	Errors    []RowError  `json:"errors"`
+}
+
+func (r *Report) add(row int, key, action string) {
+	r.Rows = append(r.Rows, RowResult{Row: row, Key: key, Action: action})
+	switch action {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+	}
+}
+
+func (r *Report) fail(row int, column, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, RowError{Row: row, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// Ошибка файла целиком (формат, колонки) — отдаётся клиенту как 400
+type FileError struct {
+	Message string
+}
+
+func (e *FileError) Error() string { return e.Message }
+
+// Параметры импорта
+type ImportOptions struct {
+	DryRun   bool   // только проверить и показать план, ничего не сохраняя
+	AuthorID string // автор новых версий техкарт и изменений цен
+}
+
+// Импорт одной сущности: разбирает строки и пишет изменения в tx
+type importer struct {
+	columns []column
+	run     func(tx *gorm.DB, rows []Row, opts ImportOptions, report *Report) (affected []string, err error)
+}
+
+func importerFor(entity Entity) (importer, error) {
+	switch entity {
+	case EntityCategories:
+		return importer{categoryColumns, importCategories}, nil
+	case EntityDishes:
+		return importer{dishColumns, importDishes}, nil
+	case EntityInventory:
+		return importer{inventoryColumns, importInventory}, nil
+	case EntityCalculations:
+		return importer{calculationColumns, importCalculations}, nil
+	default:
+		return importer{}, &FileError{Message: fmt.Sprintf("неизвестная сущность %q: допустимы categories, dishes, inventory, calculations", entity)}
+	}
+}
+
+// Маркер отката: предпросмотр и импорт с ошибками не оставляют следов в БД
+var errRollback = errors.New("rollback")
+
+// 📥 Импорт файла. Все строки проверяются и применяются в одной транзакции:
+// при любой ошибке в строках (или в режиме DryRun) транзакция откатывается,
+// а отчёт показывает, что было бы создано и обновлено.
+func Import(db *gorm.DB, entity Entity, format Format, data []byte, opts ImportOptions) (*Report, error) {
+	imp, err := importerFor(entity)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := Decode(format, data)
+	if err != nil {
+		return nil, &FileError{Message: err.Error()}
+	}
+	if len(rows) == 0 {
+		return nil, &FileError{Message: "файл не содержит строк"}
+	}
+	if err := checkRequiredColumns(imp.columns, rows); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Entity: entity, DryRun: opts.DryRun, Total: len(rows), Rows: []RowResult{}, Errors: []RowError{}}
+	var affected []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		ids, err := imp.run(tx, rows, opts, report)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun || len(report.Errors) > 0 {
+			return errRollback
+		}
+		affected = ids
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	report.Applied = err == nil
+	if report.Applied {
+		afterImport(db, entity, affected)
+	}
+	return report, nil
+}
+
+// После применения: себестоимость блюд и стоп-лист зависят от склада и техкарт
+func afterImport(db *gorm.DB, entity Entity, ids []string) {
+	var dishIDs []string // nil — все блюда с техкартами
+	switch entity {
+	case EntityDishes, EntityCalculations:
+		if len(ids) == 0 {
+			return
+		}
+		dishIDs = ids
+	case EntityInventory:
+	default:
+		return
+	}
+	if _, err := utils.RecalculateDishCosts(db, dishIDs); err != nil {
+		log.Printf("⚠️ Не удалось пересчитать себестоимость после импорта: %v", err)
+	}
+	if entity == EntityDishes {
+		return
+	}
+	if err := utils.RefreshStopList(db, dishIDs); err != nil {
+		log.Printf("⚠️ Не удалось обновить стоп-лист после импорта: %v", err)
+	}
+}
+
+func checkRequiredColumns(columns []column, rows []Row) error {
+	var missing []string
+	for _, col := range columns {
+		if col.required && !rows[0].Has(col.name) {
+			missing = append(missing, col.name)
+		}
+	}
+	if len(missing) > 0 {
+		return &FileError{Message: "нет обязательных колонок: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// Естественный ключ: регистр и пробелы по краям не важны
+func naturalKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Повторы ключа внутри файла — ошибка: неясно, какая строка главная
+type keySeen map[string]int
+
+func (k keySeen) check(report *Report, row Row, column, key string) bool {
+	if first, ok := k[naturalKey(key)]; ok {
+		report.fail(row.Line, column, "значение %q уже встречалось в строке %d", key, first)
+		return false
+	}
+	k[naturalKey(key)] = row.Line
+	return true
+}
+
+// Разбор ячеек. Каждая функция записывает ошибку в отчёт и возвращает ok=false.
+
+func cellInt(report *Report, row Row, name string, min int) (int, bool) {
+	raw := row.Get(name)
+	if raw == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		// XLSX и JSON отдают целые числа как "250" или "250.0"
+		f, ferr := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+		if ferr != nil || f != float64(int(f)) {
+			report.fail(row.Line, name, "ожидается целое число, получено %q", raw)
+			return 0, false
+		}
+		v = int(f)
+	}
+	if v < min {
+		report.fail(row.Line, name, "значение должно быть не меньше %d", min)
+		return 0, false
+	}
+	return v, true
+}
+
+func cellFloat(report *Report, row Row, name string, min, max float64) (float64, bool) {
+	raw := row.Get(name)
+	if raw == "" {
+		return 0, true
+	}
+	v, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+	if err != nil {
+		report.fail(row.Line, name, "ожидается число, получено %q", raw)
+		return 0, false
+	}
+	if v < min || v > max {
+		report.fail(row.Line, name, "значение должно быть от %g до %g", min, max)
+		return 0, false
+	}
+	return v, true
+}
+
+func cellMoney(report *Report, row Row, name string) (models.Money, bool) {
+	raw := strings.Replace(row.Get(name), ",", ".", 1)
+	if raw == "" {
+		return 0, true
+	}
+	v, err := models.ParseMoney(raw)
+	if err != nil {
+		report.fail(row.Line, name, "неверная сумма %q", raw)
+		return 0, false
+	}
+	if v < 0 {
+		report.fail(row.Line, name, "сумма не может быть отрицательной")
+		return 0, false
+	}
+	return v, true
+}
+
+func cellBool(report *Report, row Row, name string, fallback bool) (bool, bool) {
+	switch strings.ToLower(row.Get(name)) {
+	case "":
+		return fallback, true
+	case "true", "1", "yes", "да", "y":
+		return true, true
+	case "false", "0", "no", "нет", "n":
+		return false, true
+	default:
+		report.fail(row.Line, name, "ожидается true/false, получено %q", row.Get(name))
+		return fallback, false
+	}
+}
+
+func cellCurrency(report *Report, row Row, name string) (string, bool) {
+	raw := strings.ToUpper(row.Get(name))
+	if raw == "" {
+		return models.DefaultCurrency, true
+	}
+	if len(raw) != 3 {
+		report.fail(row.Line, name, "код валюты из трёх букв, например RUB")
+		return "", false
+	}
+	return raw, true
+}
+
+func cellList(row Row, name string) []string {
+	var result []string
+	for _, part := range strings.FieldsFunc(row.Get(name), func(r rune) bool { return r == ',' || r == ';' }) {
+		if p := strings.TrimSpace(part); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package transfer
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils/emoji"
+)
+
+// Склад: ключ — название продукта. Эмодзи и складская категория
+// подбираются автоматически, если в файле их нет.
+var inventoryColumns = []column{
+	{name: "product_name", required: true},
+	{name: "weight_grams", kind: kindNumber},
+	{name: "price_per_kg", kind: kindNumber},
+	{name: "currency"},
+	{name: "available", kind: kindBool},
+	{name: "category"},
+	{name: "emoji"},
+	{name: "kcal_per_100g", kind: kindNumber},
+	{name: "protein_per_100g", kind: kindNumber},
+	{name: "fat_per_100g", kind: kindNumber},
+	{name: "carbs_per_100g", kind: kindNumber},
+	{name: "allergens", kind: kindList},
+	{name: "vegetarian", kind: kindBool},
+	{name: "vegan", kind: kindBool},
+}
+
+func importInventory(tx *gorm.DB, rows []Row, _ ImportOptions, report *Report) ([]string, error) {
+	seen := keySeen{}
+	var ids []string
+
+	for _, row := range rows {
+		name := row.Get("product_name")
+		if name == "" {
+			report.fail(row.Line, "product_name", "название продукта обязательно")
+			continue
+		}
+		if !seen.check(report, row, "product_name", name) {
+			continue
+		}
+		errCount := len(report.Errors)
+		weight, _ := cellInt(report, row, "weight_grams", 0)
+		price, _ := cellMoney(report, row, "price_per_kg")
+		currency, _ := cellCurrency(report, row, "currency")
+		available, _ := cellBool(report, row, "available", true)
+		kcal, _ := cellFloat(report, row, "kcal_per_100g", 0, 900)
+		protein, _ := cellFloat(report, row, "protein_per_100g", 0, 100)
+		fat, _ := cellFloat(report, row, "fat_per_100g", 0, 100)
+		carbs, _ := cellFloat(report, row, "carbs_per_100g", 0, 100)
+		vegetarian, _ := cellBool(report, row, "vegetarian", false)
+		vegan, _ := cellBool(report, row, "vegan", false)
+		// Пустая ячейка — аллергены не проверены, «-» или «нет» — аллергенов нет
+		allergens := cellList(row, "allergens")
+		if noAllergens[strings.ToLower(row.Get("allergens"))] {
+			allergens = []string{}
+		}
+		for i, code := range allergens {
+			allergens[i] = strings.ToLower(code)
+			if !models.IsAllergenCode(allergens[i]) {
+				report.fail(row.Line, "allergens", "неизвестный аллерген %q", code)
+			}
+		}
+		if len(report.Errors) > errCount {
+			continue
+		}
+
+		var item models.InventoryItem
+		action := ActionUpdate
+		err := tx.Where("LOWER(product_name) = ?", naturalKey(name)).First(&item).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			action = ActionCreate
+			item = models.InventoryItem{
+				Currency:  models.DefaultCurrency,
+				Available: true,
+				Emoji:     emoji.GenerateEmoji(name),
+			}
+			category := emoji.GenerateCategory(name)
+			item.Category = &category
+		case err != nil:
+			return nil, err
+		}
+
+		before := item
+		item.ProductName = name
+		if row.Filled("weight_grams") {
+			item.WeightGrams = weight
+		}
+		if row.Filled("price_per_kg") {
+			item.PricePerKg = price
+		}
+		if row.Filled("currency") {
+			item.Currency = currency
+		}
+		if row.Filled("available") {
+			item.Available = available
+		}
+		if v := row.Get("category"); v != "" {
+			item.Category = &v
+		}
+		if v := row.Get("emoji"); v != "" {
+			item.Emoji = v
+		}
+		if row.Filled("kcal_per_100g") {
+			item.KcalPer100g = kcal
+		}
+		if row.Filled("protein_per_100g") {
+			item.ProteinPer100g = protein
+		}
+		if row.Filled("fat_per_100g") {
+			item.FatPer100g = fat
+		}
+		if row.Filled("carbs_per_100g") {
+			item.CarbsPer100g = carbs
+		}
+		if row.Has("allergens") {
+			item.Allergens = allergens
+		}
+		if row.Filled("vegetarian") {
+			item.Vegetarian = vegetarian
+		}
+		if row.Filled("vegan") {
+			item.Vegan = vegan
+		}
+
+		if action == ActionUpdate && reflect.DeepEqual(before, item) {
+			report.add(row.Line, name, ActionUnchanged)
+			continue
+		}
+
+		if err := tx.Save(&item).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, item.ID)
+		report.add(row.Line, name, action)
+	}
+	return ids, nil
+}
+
+func exportInventory(db *gorm.DB, filter ExportFilter) ([][]string, error) {
+	q := db.Model(&models.InventoryItem{})
+	if filter.Available != nil {
+		q = q.Where("available = ?", *filter.Available)
+	}
+	if filter.InventoryCategory != "" {
+		q = q.Where("category = ?", filter.InventoryCategory)
+	}
+	var items []models.InventoryItem
+	if err := q.Order("product_name").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(items))
+	for _, item := range items {
+		category := ""
+		if item.Category != nil {
+			category = *item.Category
+		}
+		records = append(records, []string{
+			item.ProductName,
+			strconv.Itoa(item.WeightGrams),
+			item.PricePerKg.String(),
+			item.Currency,
+			strconv.FormatBool(item.Available),
+			category,
+			item.Emoji,
+			formatFloat(item.KcalPer100g),
+			formatFloat(item.ProteinPer100g),
+			formatFloat(item.FatPer100g),
+			formatFloat(item.CarbsPer100g),
+			formatAllergens(item.Allergens),
+			strconv.FormatBool(item.Vegetarian),
+			strconv.FormatBool(item.Vegan),
+		})
+	}
+	return records, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Отметки «аллергенов нет» в ячейке allergens
+var noAllergens = map[string]bool{"-": true, "нет": true, "none": true}
+
+// nil (не проверены) — пустая ячейка, пустой список — «-»
+func formatAllergens(codes []string) string {
+	if codes != nil && len(codes) == 0 {
+		return "-"
+	}
+	return strings.Join(codes, ",")
+}
@@ -0,0 +1,50 @@
+package transfer
+
+import (
+	"io"
+	"strconv"
+
+	"monolith/menu-service/models"
+)
+
+// Колонки выгрузки отчёта меню-инжиниринга
+var menuEngineeringColumns = []column{
+	{name: "name"},
+	{name: "category"},
+	{name: "class"},
+	{name: "popularity"},
+	{name: "profitability"},
+	{name: "sold", kind: kindNumber},
+	{name: "menu_mix_percent", kind: kindNumber},
+	{name: "price", kind: kindNumber},
+	{name: "food_cost", kind: kindNumber},
+	{name: "food_cost_percent", kind: kindNumber},
+	{name: "margin", kind: kindNumber},
+	{name: "revenue", kind: kindNumber},
+	{name: "total_margin", kind: kindNumber},
+	{name: "recommendation"},
+}
+
+// 📤 Выгрузить отчёт меню-инжиниринга (CSV, XLSX или JSON-таблица)
+func ExportMenuEngineering(w io.Writer, format Format, report *models.MenuEngineeringReport) error {
+	records := make([][]string, 0, len(report.Items))
+	for _, item := range report.Items {
+		records = append(records, []string{
+			item.Name,
+			item.CategoryName,
+			item.Class,
+			item.Popularity,
+			item.Profitability,
+			strconv.FormatInt(item.Sold, 10),
+			strconv.FormatFloat(item.MenuMixPercent, 'f', 1, 64),
+			item.Price.String(),
+			item.FoodCost.String(),
+			strconv.FormatFloat(item.FoodCostPercent, 'f', 1, 64),
+			item.Margin.String(),
+			item.Revenue.String(),
+			item.TotalMargin.String(),
+			item.Recommendation,
+		})
+	}
+	return Encode(w, format, menuEngineeringColumns, records)
+}
@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+// Часовой пояс ресторана; задаётся в Init
+var restaurantLocation = time.Local
+
+// Часовой пояс ресторана из RESTAURANT_TIMEZONE (по умолчанию Europe/Moscow)
+func loadRestaurantLocation() *time.Location {
+	name := os.Getenv("RESTAURANT_TIMEZONE")
+	if name == "" {
+		name = "Europe/Moscow"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️ Неизвестный часовой пояс %q, используем локальный: %v", name, err)
+		return time.Local
+	}
+	return loc
+}
+
+// Текущее время в часовом поясе ресторана
+func RestaurantNow() time.Time {
+	return time.Now().In(restaurantLocation)
+}
+
+// Время расписания без часового пояса считается временем ресторана
+var scheduleLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// Разобрать время плановой публикации: "2026-06-01T08:00:00+03:00" (RFC 3339) берётся как есть,
+// "2026-06-01 08:00" — в часовом поясе ресторана, как и окна доступности. Пустая строка — nil.
+func ParseScheduleTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	for _, layout := range scheduleLayouts {
+		if t, err := time.ParseInLocation(layout, value, restaurantLocation); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, validationErrorf("время %q должно быть в формате ГГГГ-ММ-ДД ЧЧ:ММ (время ресторана) или RFC 3339", value)
+}
+
+// Разобрать пару «опубликовать / снять с публикации»; снятие должно быть позже публикации
+func ParsePublishSchedule(publishAt, unpublishAt string) (*time.Time, *time.Time, error) {
+	publish, err := ParseScheduleTime(publishAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	unpublish, err := ParseScheduleTime(unpublishAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if publish != nil && unpublish != nil && !unpublish.After(*publish) {
+		return nil, nil, validationErrorf("unpublish_at должен быть позже publish_at")
+	}
+	return publish, unpublish, nil
+}
+
+// Проверить поля окна доступности
+func ValidateAvailabilityWindow(w *models.AvailabilityWindow) error {
+	for _, day := range w.Weekdays {
+		if day < 1 || day > 7 {
+			return validationErrorf("дни недели задаются числами от 1 (понедельник) до 7 (воскресенье)")
+		}
+	}
+	if (w.StartTime == "") != (w.EndTime == "") {
+		return validationErrorf("start_time и end_time задаются вместе")
+	}
+	for _, v := range []string{w.StartTime, w.EndTime} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(clockLayout, v); err != nil {
+			return validationErrorf("время %q должно быть в формате ЧЧ:ММ", v)
+		}
+	}
+	for _, v := range []string{w.StartDate, w.EndDate} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, v); err != nil {
+			return validationErrorf("дата %q должна быть в формате ГГГГ-ММ-ДД", v)
+		}
+	}
+	if w.StartDate != "" && w.EndDate != "" && w.StartDate > w.EndDate {
+		return validationErrorf("start_date не может быть позже end_date")
+	}
+	return nil
+}
+
+// Попадает ли момент (в часовом поясе ресторана) в окно
+func windowContains(w models.AvailabilityWindow, now time.Time) bool {
+	today := now.Format(dateLayout)
+	if w.StartDate != "" && today < w.StartDate {
+		return false
+	}
+	if w.EndDate != "" && today > w.EndDate {
+		return false
+	}
+
+	if len(w.Weekdays) > 0 {
+		weekday := int(now.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		matched := false
+		for _, day := range w.Weekdays {
+			if day == weekday {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if w.StartTime == "" {
+		return true
+	}
+	clock := now.Format(clockLayout)
+	if w.StartTime <= w.EndTime {
+		return clock >= w.StartTime && clock < w.EndTime
+	}
+	// окно через полночь: 22:00–02:00
+	return clock >= w.StartTime || clock < w.EndTime
+}
+
+// 🕗 Расписание доступности блюд и категорий
+type AvailabilitySchedule struct {
+	byItem     map[string][]models.AvailabilityWindow
+	byCategory map[string][]models.AvailabilityWindow
+	categories *categoryGraph
+}
+
+// Загрузить все окна доступности
+func LoadAvailabilitySchedule(db *gorm.DB) (*AvailabilitySchedule, error) {
+	var windows []models.AvailabilityWindow
+	if err := db.Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	graph, err := loadCategoryGraph(db)
+	if err != nil {
+		return nil, err
+	}
+	s := &AvailabilitySchedule{
+		byItem:     map[string][]models.AvailabilityWindow{},
+		byCategory: map[string][]models.AvailabilityWindow{},
+		categories: graph,
+	}
+	for _, w := range windows {
+		if w.MenuItemID != nil {
+			s.byItem[*w.MenuItemID] = append(s.byItem[*w.MenuItemID], w)
+		}
+		if w.CategoryID != nil {
+			s.byCategory[*w.CategoryID] = append(s.byCategory[*w.CategoryID], w)
+		}
+	}
+	return s, nil
+}
+
+// Доступно ли блюдо в момент now.
+// Окна блюда, его категории и всех родительских категорий проверяются независимо:
+// нужно попасть в каждый набор. Блюда скрытых категорий недоступны.
+func (s *AvailabilitySchedule) Available(menuItemID, categoryID string, now time.Time) bool {
+	if s.CategoryHidden(categoryID) || !anyWindowContains(s.byItem[menuItemID], now) {
+		return false
+	}
+	for _, id := range s.categories.chain(categoryID) {
+		if !anyWindowContains(s.byCategory[id], now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Скрыта ли категория (сама или через родителя)
+func (s *AvailabilitySchedule) CategoryHidden(categoryID string) bool {
+	return s.categories.isHidden(categoryID)
+}
+
+func anyWindowContains(windows []models.AvailabilityWindow, now time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if windowContains(w, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Причины, по которым блюда нельзя заказать прямо сейчас.
+// В результате только недоступные блюда: снятые с публикации, из скрытой категории,
+// вне расписания или в стоп-листе.
+func UnavailableReasons(db *gorm.DB, menuItemIDs []string) (map[string]string, error) {
+	return UnavailableReasonsAt(db, "", menuItemIDs)
+}
+
+// То же для конкретной точки: учитываются скрытые в точке блюда и её остатки
+func UnavailableReasonsAt(db *gorm.DB, locationID string, menuItemIDs []string) (map[string]string, error) {
+	reasons := map[string]string{}
+	if len(menuItemIDs) == 0 {
+		return reasons, nil
+	}
+
+	closed := false
+	if locationID != "" {
+		var location models.Location
+		if err := db.Select("id", "archived").First(&location, "id = ?", locationID).Error; err != nil {
+			return nil, err
+		}
+		closed = location.Archived
+	}
+	locationMenu, err := LoadLocationMenu(db, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.MenuItem
+	if err := db.Select("id", "published", "category_id").Where("id IN ?", menuItemIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	schedule, err := LoadAvailabilitySchedule(db)
+	if err != nil {
+		return nil, err
+	}
+	stopped, err := StoppedMenuItemIDsAt(db, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(items))
+	now := RestaurantNow()
+	for _, item := range items {
+		found[item.ID] = true
+		switch {
+		case closed:
+			reasons[item.ID] = "точка не принимает заказы"
+		case !item.Published:
+			reasons[item.ID] = "не опубликовано"
+		case locationMenu.Hidden(item.ID):
+			reasons[item.ID] = "не продаётся в этой точке"
+		case schedule.CategoryHidden(item.CategoryID):
+			reasons[item.ID] = "категория скрыта"
+		case !schedule.Available(item.ID, item.CategoryID, now):
+			reasons[item.ID] = "вне времени доступности"
+		case stopped[item.ID]:
+			reasons[item.ID] = "в стоп-листе"
+		}
+	}
+	for _, id := range menuItemIDs {
+		if !found[id] {
+			reasons[id] = "блюдо не найдено"
+		}
+	}
+	return reasons, nil
+}
+
+// ⏰ Применить плановую публикацию и снятие с публикации блюд и категорий.
+// Категория «публикуется» снятием флага hidden и «снимается» его установкой.
+func ApplyPublishSchedule(db *gorm.DB) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MenuItem{}).
+			Where("publish_at IS NOT NULL AND publish_at <= ?", now).
+			Updates(map[string]interface{}{"published": true, "publish_at": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.MenuItem{}).
+			Where("unpublish_at IS NOT NULL AND unpublish_at <= ?", now).
+			Updates(map[string]interface{}{"published": false, "unpublish_at": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Category{}).
+			Where("publish_at IS NOT NULL AND publish_at <= ?", now).
+			Updates(map[string]interface{}{"hidden": false, "publish_at": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Category{}).
+			Where("unpublish_at IS NOT NULL AND unpublish_at <= ?", now).
+			Updates(map[string]interface{}{"hidden": true, "unpublish_at": nil}).Error
+	})
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"math"
+	"strings"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Продукты склада для сопоставления с ингредиентами: по ID и по названию
+type productIndex struct {
+	byID   map[string]models.InventoryItem
+	byName map[string]models.InventoryItem
+}
+
+func productKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Найти продукт ингредиента: сначала по ID склада, затем по названию
+func (p productIndex) find(ing models.CalculationIngredient) (models.InventoryItem, bool) {
+	if ing.InventoryItemID != nil && *ing.InventoryItemID != "" {
+		if product, ok := p.byID[*ing.InventoryItemID]; ok {
+			return product, true
+		}
+	}
+	product, ok := p.byName[productKey(ing.ProductName)]
+	return product, ok
+}
+
+// Загрузить продукты склада, упомянутые в ингредиентах
+func loadProducts(db *gorm.DB, ingredients []models.CalculationIngredient) (productIndex, error) {
+	index := productIndex{
+		byID:   map[string]models.InventoryItem{},
+		byName: map[string]models.InventoryItem{},
+	}
+
+	var ids, names []string
+	for _, ing := range ingredients {
+		if ing.InventoryItemID != nil && *ing.InventoryItemID != "" {
+			ids = append(ids, *ing.InventoryItemID)
+		}
+		if ing.ProductName != "" {
+			names = append(names, productKey(ing.ProductName))
+		}
+	}
+	if len(ids) == 0 && len(names) == 0 {
+		return index, nil
+	}
+
+	query := db.Where("lower(product_name) IN ?", append(names, ""))
+	if len(ids) > 0 {
+		query = query.Or("id IN ?", ids)
+	}
+	var items []models.InventoryItem
+	if err := query.Find(&items).Error; err != nil {
+		return index, err
+	}
+	for _, item := range items {
+		index.byID[item.ID] = item
+		index.byName[productKey(item.ProductName)] = item
+	}
+	return index, nil
+}
+
+// Продукты для набора калькуляций
+func loadProductsForCalculations(db *gorm.DB, calcs map[string]models.Calculation) (productIndex, error) {
+	var all []models.CalculationIngredient
+	for _, calc := range calcs {
+		all = append(all, calc.Ingredients...)
+	}
+	return loadProducts(db, all)
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// ⚙️ Расчёт техкарты на сервере.
+// Для каждого ингредиента: продукт склада и его текущая цена, вес нетто,
+// цена кг нетто и стоимость закладки. Для блюда: итоги брутто/нетто, выход и себестоимость.
+// Значения цен и стоимости, присланные клиентом, перезаписываются.
+func ComputeCalculation(db *gorm.DB, calc *models.Calculation) error {
+	if len(calc.Ingredients) == 0 {
+		return validationErrorf("калькуляция должна содержать хотя бы один ингредиент")
+	}
+	if calc.TotalOutputGrams < 0 {
+		return validationErrorf("выход блюда не может быть отрицательным")
+	}
+
+	products, err := loadProducts(db, calc.Ingredients)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	seen := map[string]bool{}
+	calc.TotalGrossGrams = 0
+	calc.TotalNetGrams = 0
+	calc.TotalCost = 0
+
+	for i := range calc.Ingredients {
+		ing := &calc.Ingredients[i]
+		if ing.AmountGrams <= 0 {
+			return validationErrorf("вес брутто ингредиента %q должен быть больше 0", ing.ProductName)
+		}
+		if ing.WastePercent < 0 || ing.WastePercent >= 100 {
+			return validationErrorf("процент отходов ингредиента %q должен быть от 0 до 100", ing.ProductName)
+		}
+
+		product, ok := products.find(*ing)
+		if !ok {
+			unknown = append(unknown, ing.ProductName)
+			continue
+		}
+		if seen[product.ID] {
+			return validationErrorf("продукт %q указан в калькуляции несколько раз", product.ProductName)
+		}
+		seen[product.ID] = true
+
+		productID := product.ID
+		ing.InventoryItemID = &productID
+		ing.ProductName = product.ProductName
+		ing.PricePerKg = product.PricePerKg
+		ing.NetGrams = round1(NetGrams(ing.AmountGrams, ing.WastePercent))
+		ing.PriceAfterWaste = product.PricePerKg.MulFloat(1 / (1 - ing.WastePercent/100))
+		ing.TotalCost = product.PricePerKg.ForGrams(ing.AmountGrams)
+
+		calc.TotalGrossGrams += ing.AmountGrams
+		calc.TotalNetGrams += ing.NetGrams
+		calc.TotalCost += ing.TotalCost
+	}
+	if len(unknown) > 0 {
+		return validationErrorf("продукты не найдены на складе: %s", strings.Join(unknown, ", "))
+	}
+
+	calc.TotalNetGrams = round1(calc.TotalNetGrams)
+	if calc.TotalOutputGrams == 0 {
+		calc.TotalOutputGrams = int(math.Round(calc.TotalNetGrams))
+	}
+	if calc.TotalNetGrams > 0 {
+		calc.YieldPercent = round1(float64(calc.TotalOutputGrams) / calc.TotalNetGrams * 100)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Действующие версии техкарт: по одной на блюдо, последняя вступившая в силу
+const activeCalculationsSQL = `SELECT DISTINCT ON (menu_item_id) * FROM menu_calculations
+	WHERE effective_from <= now()
+	ORDER BY menu_item_id, effective_from DESC, version DESC`
+
+// Действующие калькуляции блюд вместе с ингредиентами
+func GetActiveCalculations(db *gorm.DB, menuItemIDs []string) (map[string]models.Calculation, error) {
+	result := make(map[string]models.Calculation, len(menuItemIDs))
+	if len(menuItemIDs) == 0 {
+		return result, nil
+	}
+
+	var calcs []models.Calculation
+	if err := db.
+		Raw(`SELECT * FROM (`+activeCalculationsSQL+`) active WHERE menu_item_id IN ?`, menuItemIDs).
+		Scan(&calcs).Error; err != nil {
+		return nil, err
+	}
+
+	calcIDs := make([]string, 0, len(calcs))
+	for _, calc := range calcs {
+		calcIDs = append(calcIDs, calc.ID)
+	}
+	var ingredients []models.CalculationIngredient
+	if len(calcIDs) > 0 {
+		if err := db.Where("calculation_id IN ?", calcIDs).Order("created_at").Find(&ingredients).Error; err != nil {
+			return nil, err
+		}
+	}
+	byCalc := make(map[string][]models.CalculationIngredient, len(calcs))
+	for _, ing := range ingredients {
+		byCalc[ing.CalculationID] = append(byCalc[ing.CalculationID], ing)
+	}
+
+	for _, calc := range calcs {
+		calc.Ingredients = byCalc[calc.ID]
+		result[calc.MenuItemID] = calc
+	}
+	return result, nil
+}
+
+// Сохранить новую версию техкарты. Предыдущие версии не изменяются.
+// Если EffectiveFrom не задан, версия действует сразу и вызывающий сам пересчитывает
+// себестоимость; версию с будущей датой активирует фоновая задача ActivateCalculations.
+func SaveDishCalculation(db *gorm.DB, calc *models.Calculation) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Номер версии — MAX+1, поэтому параллельные сохранения одного блюда идут по очереди
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&models.MenuItem{}, "id = ?", calc.MenuItemID).Error; err != nil {
+			return err
+		}
+
+		var maxVersion int
+		if err := tx.Model(&models.Calculation{}).
+			Where("menu_item_id = ?", calc.MenuItemID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		calc.ID = ""
+		calc.Version = maxVersion + 1
+		calc.CreatedAt = now
+		if calc.EffectiveFrom.IsZero() {
+			calc.EffectiveFrom = now
+		}
+		calc.ActivatedAt = nil
+		if !calc.EffectiveFrom.After(now) {
+			calc.ActivatedAt = &now
+		}
+		for i := range calc.Ingredients {
+			calc.Ingredients[i].ID = ""
+			calc.Ingredients[i].CalculationID = ""
+			calc.Ingredients[i].CreatedAt = now
+		}
+		return tx.Create(calc).Error
+	})
+}
+
+// ⏰ Применить версии техкарт, дата начала действия которых наступила:
+// пересчитать себестоимость и маржу блюд и стоп-лист, затем отметить версии применёнными
+func ActivateCalculations(db *gorm.DB) error {
+	now := time.Now()
+	var menuItemIDs []string
+	if err := db.Model(&models.Calculation{}).
+		Where("activated_at IS NULL AND effective_from <= ?", now).
+		Distinct().Pluck("menu_item_id", &menuItemIDs).Error; err != nil {
+		return err
+	}
+	if len(menuItemIDs) == 0 {
+		return nil
+	}
+
+	if _, err := RecalculateDishCosts(db, menuItemIDs); err != nil {
+		return err
+	}
+	if err := RefreshStopList(db, menuItemIDs); err != nil {
+		return err
+	}
+	return db.Model(&models.Calculation{}).
+		Where("activated_at IS NULL AND effective_from <= ? AND menu_item_id IN ?", now, menuItemIDs).
+		Update("activated_at", now).Error
+}
+
+// Список версий техкарты (без ингредиентов), от новых к старым
+func ListCalculationVersions(db *gorm.DB, menuItemID string) ([]models.Calculation, error) {
+	versions := []models.Calculation{}
+	err := db.
+		Where("menu_item_id = ?", menuItemID).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// Конкретная версия техкарты с ингредиентами
+func GetCalculationVersion(db *gorm.DB, menuItemID string, version int) (*models.Calculation, error) {
+	var calc models.Calculation
+	if err := db.
+		Preload("Ingredients", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("menu_item_id = ? AND version = ?", menuItemID, version).
+		First(&calc).Error; err != nil {
+		return nil, err
+	}
+	return &calc, nil
+}
+
+// Откат: копия старой версии сохраняется как новая, история остаётся линейной
+func RollbackCalculation(db *gorm.DB, menuItemID string, version int, authorID string) (*models.Calculation, error) {
+	old, err := GetCalculationVersion(db, menuItemID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	calc := &models.Calculation{
+		MenuItemID:       menuItemID,
+		AuthorID:         authorID,
+		Comment:          fmt.Sprintf("Откат к версии %d", version),
+		CookingNotes:     old.CookingNotes,
+		TotalGrossGrams:  old.TotalGrossGrams,
+		TotalNetGrams:    old.TotalNetGrams,
+		TotalOutputGrams: old.TotalOutputGrams,
+		YieldPercent:     old.YieldPercent,
+		TotalCost:        old.TotalCost,
+		Ingredients:      old.Ingredients,
+	}
+	if err := SaveDishCalculation(db, calc); err != nil {
+		return nil, err
+	}
+	return calc, nil
+}
+
+// Разница между версиями: добавленные, удалённые и изменённые ингредиенты
+func DiffCalculations(from, to *models.Calculation) (models.CalculationDiff, error) {
+	if from.MenuItemID != to.MenuItemID {
+		return models.CalculationDiff{}, errors.New("версии относятся к разным блюдам")
+	}
+
+	diff := models.CalculationDiff{
+		MenuItemID:        to.MenuItemID,
+		FromVersion:       from.Version,
+		ToVersion:         to.Version,
+		OutputGramsBefore: from.TotalOutputGrams,
+		OutputGramsAfter:  to.TotalOutputGrams,
+		TotalCostBefore:   from.TotalCost,
+		TotalCostAfter:    to.TotalCost,
+		Added:             []models.CalculationIngredient{},
+		Removed:           []models.CalculationIngredient{},
+		Changed:           []models.IngredientChange{},
+	}
+
+	key := func(ing models.CalculationIngredient) string {
+		return strings.ToLower(strings.TrimSpace(ing.ProductName))
+	}
+	before := make(map[string]models.CalculationIngredient, len(from.Ingredients))
+	for _, ing := range from.Ingredients {
+		before[key(ing)] = ing
+	}
+	after := make(map[string]models.CalculationIngredient, len(to.Ingredients))
+	for _, ing := range to.Ingredients {
+		after[key(ing)] = ing
+	}
+
+	for k, ing := range after {
+		old, ok := before[k]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, ing)
+		case old.AmountGrams != ing.AmountGrams ||
+			old.PricePerKg != ing.PricePerKg ||
+			old.WastePercent != ing.WastePercent ||
+			old.TotalCost != ing.TotalCost:
+			diff.Changed = append(diff.Changed, models.IngredientChange{ProductName: ing.ProductName, Before: old, After: ing})
+		}
+	}
+	for k, ing := range before {
+		if _, ok := after[k]; !ok {
+			diff.Removed = append(diff.Removed, ing)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ProductName < diff.Added[j].ProductName })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ProductName < diff.Removed[j].ProductName })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ProductName < diff.Changed[j].ProductName })
+	return diff, nil
+}
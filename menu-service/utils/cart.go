@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"errors"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Корзина собирается в одной точке
+var ErrCartOtherLocation = errors.New("в корзине блюда из другой точки — очистите корзину, чтобы сменить точку")
+
+// Выбрать точку корзины. Сменить точку можно только в пустой корзине
+func SetCartLocation(db *gorm.DB, cart *models.Cart, locationID string) error {
+	current := ""
+	if cart.LocationID != nil {
+		current = *cart.LocationID
+	}
+	if locationID == current {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&models.CartItem{}).Where("cart_id = ?", cart.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCartOtherLocation
+	}
+	var value *string
+	if locationID != "" {
+		value = &locationID
+	}
+	if err := db.Model(cart).Update("location_id", value).Error; err != nil {
+		return err
+	}
+	cart.LocationID = value
+	return nil
+}
+
+// Положить позицию в корзину: то же блюдо (или комбо) с тем же выбором увеличивает количество,
+// цена и снимок выбора обновляются до текущих
+func AddCartLine(db *gorm.DB, line models.CartItem) (models.CartItem, error) {
+	var item models.CartItem
+	err := db.
+		Where("cart_id = ? AND menu_item_id = ? AND modifiers_key = ?", line.CartID, line.MenuItemID, line.ModifiersKey).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Create(&line).Error
+		return line, err
+	}
+	if err != nil {
+		return item, err
+	}
+	item.Quantity += line.Quantity
+	item.Price = line.Price
+	item.Name = line.Name
+	item.Modifiers = line.Modifiers
+	item.ComboItems = line.ComboItems
+	err = db.Save(&item).Error
+	return item, err
+}
+
+// 🔁 Повторить заказ: позиции старого заказа добавляются в корзину по текущим ценам.
+// Блюда и комбо, которые сейчас нельзя заказать, в корзину не попадают и перечисляются в отчёте.
+// Корзина пополняется в одной транзакции: при ошибке БД она остаётся прежней.
+func ReorderIntoCart(db *gorm.DB, cart *models.Cart, order models.Order) (*models.ReorderReport, error) {
+	var report *models.ReorderReport
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = reorderIntoCart(tx, cart, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func reorderIntoCart(db *gorm.DB, cart *models.Cart, order models.Order) (*models.ReorderReport, error) {
+	report := &models.ReorderReport{
+		OrderID:     order.ID,
+		Added:       []models.ReorderLine{},
+		Unavailable: []models.ReorderLine{},
+	}
+	locationID := ""
+	if cart.LocationID != nil {
+		locationID = *cart.LocationID
+	}
+
+	// Блюда одного комбо собираются обратно в одну позицию
+	combos := map[string][]models.OrderItem{}
+	var comboOrder []string
+	for _, item := range order.Items {
+		if item.ComboID == nil || item.ComboLine == "" {
+			continue
+		}
+		if _, ok := combos[item.ComboLine]; !ok {
+			comboOrder = append(comboOrder, item.ComboLine)
+		}
+		combos[item.ComboLine] = append(combos[item.ComboLine], item)
+	}
+
+	for _, item := range order.Items {
+		if item.ComboID != nil {
+			continue
+		}
+		optionIDs := make([]string, 0, len(item.Modifiers))
+		for _, m := range item.Modifiers {
+			optionIDs = append(optionIDs, m.OptionID)
+		}
+		entry := models.ReorderLine{
+			MenuItemID: item.MenuItemID,
+			Name:       item.Name,
+			Quantity:   item.Quantity,
+			OldPrice:   item.Price,
+		}
+		line, err := PriceCartLine(db, locationID, item.MenuItemID, optionIDs)
+		if err != nil {
+			if !recordUnavailable(report, entry, err) {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := AddCartLine(db, models.CartItem{
+			CartID:       cart.ID,
+			MenuItemID:   item.MenuItemID,
+			Name:         line.MenuItem.Name,
+			Quantity:     item.Quantity,
+			Price:        line.UnitPrice,
+			Modifiers:    line.Modifiers,
+			ModifiersKey: line.ModifiersKey,
+		}); err != nil {
+			return nil, err
+		}
+		entry.Name = line.MenuItem.Name
+		entry.Price = line.UnitPrice
+		recordAdded(report, entry)
+	}
+
+	for _, key := range comboOrder {
+		components := combos[key]
+		first := components[0]
+		entry := models.ReorderLine{
+			MenuItemID: *first.ComboID,
+			Name:       first.ComboName,
+			Quantity:   first.Quantity,
+		}
+		for _, c := range components {
+			entry.OldPrice += c.Price
+			// В позиции комбо модификаторов блюд нет — сообщаем, что они не перенесены
+			for _, m := range c.Modifiers {
+				entry.DroppedModifiers = append(entry.DroppedModifiers, c.Name+": "+m.OptionName)
+			}
+		}
+
+		selection, err := comboSelection(db, *first.ComboID, components)
+		if err != nil {
+			if !recordUnavailable(report, entry, err) {
+				return nil, err
+			}
+			continue
+		}
+		line, err := PriceComboLine(db, locationID, *first.ComboID, selection)
+		if err != nil {
+			if !recordUnavailable(report, entry, err) {
+				return nil, err
+			}
+			continue
+		}
+		comboID := line.Combo.ID
+		if _, err := AddCartLine(db, models.CartItem{
+			CartID:       cart.ID,
+			MenuItemID:   comboID,
+			Name:         line.Combo.Name,
+			Quantity:     first.Quantity,
+			Price:        line.UnitPrice,
+			ModifiersKey: line.Key,
+			ComboID:      &comboID,
+			ComboItems:   line.Items,
+		}); err != nil {
+			return nil, err
+		}
+		entry.Name = line.Combo.Name
+		entry.Price = line.UnitPrice
+		recordAdded(report, entry)
+	}
+	return report, nil
+}
+
+func recordAdded(report *models.ReorderReport, entry models.ReorderLine) {
+	if entry.Price != entry.OldPrice {
+		report.PriceChanged = true
+	}
+	report.Added = append(report.Added, entry)
+}
+
+// Ошибку проверки записать как недоступную позицию; false — ошибка не связана с выбором
+func recordUnavailable(report *models.ReorderReport, entry models.ReorderLine, err error) bool {
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		return false
+	}
+	entry.Reason = vErr.Message
+	report.Unavailable = append(report.Unavailable, entry)
+	return true
+}
+
+// Выбор в слотах текущего комбо по блюдам из старого заказа
+func comboSelection(db *gorm.DB, comboID string, components []models.OrderItem) (map[string]string, error) {
+	combo, err := GetCombo(db, comboID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, validationErrorf("комбо больше не продаётся")
+		}
+		return nil, err
+	}
+	selection := map[string]string{}
+	for _, component := range components {
+		found := false
+		for _, slot := range combo.Slots {
+			if _, taken := selection[slot.ID]; taken {
+				continue
+			}
+			for _, option := range slot.Items {
+				if option.MenuItemID == component.MenuItemID {
+					selection[slot.ID] = component.MenuItemID
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, validationErrorf("блюдо %q больше не входит в комбо", component.Name)
+		}
+	}
+	return selection, nil
+}
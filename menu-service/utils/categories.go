@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+)
+
+// Глубина вложенности категорий ограничена, чтобы дерево оставалось читаемым на витрине
+const maxCategoryDepth = 5
+
+// 🌳 Связи категорий: родители и скрытость — для проверок без рекурсивных запросов
+type categoryGraph struct {
+	parent map[string]string
+	hidden map[string]bool
+}
+
+func loadCategoryGraph(db *gorm.DB) (*categoryGraph, error) {
+	var categories []models.Category
+	if err := db.Select("id", "parent_id", "hidden").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	g := &categoryGraph{parent: map[string]string{}, hidden: map[string]bool{}}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			g.parent[c.ID] = *c.ParentID
+		}
+		g.hidden[c.ID] = c.Hidden
+	}
+	return g, nil
+}
+
+// Цепочка от категории к корню (сама категория первая); защищена от циклов
+func (g *categoryGraph) chain(id string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for id != "" && !seen[id] {
+		seen[id] = true
+		ids = append(ids, id)
+		id = g.parent[id]
+	}
+	return ids
+}
+
+// Входит ли категория в поддерево ancestorID (включая её саму)
+func (g *categoryGraph) within(id, ancestorID string) bool {
+	for _, c := range g.chain(id) {
+		if c == ancestorID {
+			return true
+		}
+	}
+	return false
+}
+
+// Скрыта ли категория сама или через любого из предков
+func (g *categoryGraph) isHidden(id string) bool {
+	for _, c := range g.chain(id) {
+		if g.hidden[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// 🔍 Проверить нового родителя категории: существует, не она сама и не её потомок
+func ValidateCategoryParent(db *gorm.DB, categoryID string, parentID *string) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == categoryID {
+		return validationErrorf("Категория не может быть родителем самой себя")
+	}
+	g, err := loadCategoryGraph(db)
+	if err != nil {
+		return err
+	}
+	if _, ok := g.hidden[*parentID]; !ok {
+		return validationErrorf("Родительская категория не найдена")
+	}
+	chain := g.chain(*parentID)
+	for _, id := range chain {
+		if categoryID != "" && id == categoryID {
+			return validationErrorf("Нельзя вложить категорию в её же подкатегорию")
+		}
+	}
+	if len(chain)+1+subtreeDepth(g, categoryID) > maxCategoryDepth {
+		return validationErrorf("Вложенность категорий не больше %d уровней", maxCategoryDepth)
+	}
+	return nil
+}
+
+// Высота поддерева под категорией (0 — нет подкатегорий)
+func subtreeDepth(g *categoryGraph, id string) int {
+	if id == "" {
+		return 0
+	}
+	depth := 0
+	for child, parent := range g.parent {
+		if parent == id {
+			if d := 1 + subtreeDepth(g, child); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth
+}
+
+// 🔢 Массовая сортировка: порядок показа = позиция ID в списке.
+// Все ID должны принадлежать одной таблице; неизвестные ID — ошибка проверки.
+func ReorderCategories(db *gorm.DB, ids []string) error {
+	return reorder(db, &models.Category{}, ids, "Категория не найдена")
+}
+
+// 🔢 Массовая сортировка блюд внутри категорий
+func ReorderMenuItems(db *gorm.DB, ids []string) error {
+	return reorder(db, &models.MenuItem{}, ids, "Блюдо не найдено")
+}
+
+func reorder(db *gorm.DB, model interface{}, ids []string, notFound string) error {
+	if len(ids) == 0 {
+		return validationErrorf("Список ids пуст")
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return validationErrorf("ID %s указан дважды", id)
+		}
+		seen[id] = true
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(model).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(ids) {
+			return validationErrorf("%s", notFound)
+		}
+		for i, id := range ids {
+			if err := tx.Model(model).Where("id = ?", id).Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 🌳 Собрать дерево видимых категорий с блюдами. Блюда уже отфильтрованы вызывающим
+// (опубликованы и доступны сейчас); пустые ветки отбрасываются, если keepEmpty=false.
+func BuildCategoryTree(categories []models.Category, items []models.MenuItem, keepEmpty bool) []models.CategoryNode {
+	sortCategories(categories)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].SortOrder != items[j].SortOrder {
+			return items[i].SortOrder < items[j].SortOrder
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	children := map[string][]models.Category{}
+	known := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	var roots []models.Category
+	for _, c := range categories {
+		if c.ParentID != nil && known[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else if c.ParentID == nil {
+			roots = append(roots, c)
+		}
+		// категории со скрытым родителем в список не попадают — их ветка скрыта целиком
+	}
+	itemsByCategory := map[string][]models.MenuItem{}
+	for _, item := range items {
+		itemsByCategory[item.CategoryID] = append(itemsByCategory[item.CategoryID], item)
+	}
+
+	var build func(list []models.Category) []models.CategoryNode
+	build = func(list []models.Category) []models.CategoryNode {
+		nodes := []models.CategoryNode{}
+		for _, c := range list {
+			node := models.CategoryNode{
+				Category: c,
+				Children: build(children[c.ID]),
+				Items:    itemsByCategory[c.ID],
+			}
+			if node.Items == nil {
+				node.Items = []models.MenuItem{}
+			}
+			if !keepEmpty && len(node.Items) == 0 && len(node.Children) == 0 {
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// Порядок показа категорий: sort_order, затем название
+func sortCategories(categories []models.Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Name < categories[j].Name
+	})
+}
@@ -0,0 +1,447 @@
+package utils
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Рассчитанная на сервере позиция корзины с комбо
+type PricedComboLine struct {
+	Combo     models.Combo
+	Items     []models.SelectedComboItem
+	Key       string // отсортированные пары слот:блюдо — различает позиции одного комбо
+	UnitPrice models.Money
+}
+
+// Загрузка комбо вместе со слотами и блюдами в порядке показа
+func comboQuery(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Slots", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, name")
+		}).
+		Preload("Slots.Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order")
+		})
+}
+
+// Получить комбо со слотами
+func GetCombo(db *gorm.DB, id string) (*models.Combo, error) {
+	var combo models.Combo
+	if err := comboQuery(db).First(&combo, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &combo, nil
+}
+
+// Список комбо; publishedOnly — только опубликованные
+func ListCombos(db *gorm.DB, publishedOnly bool) ([]models.Combo, error) {
+	combos := []models.Combo{}
+	query := comboQuery(db).Order("sort_order, name")
+	if publishedOnly {
+		query = query.Where("published = TRUE")
+	}
+	err := query.Find(&combos).Error
+	return combos, err
+}
+
+// Проверить комбо перед сохранением
+func ValidateCombo(db *gorm.DB, combo *models.Combo) error {
+	combo.Name = strings.TrimSpace(combo.Name)
+	if combo.Name == "" {
+		return validationErrorf("название комбо обязательно")
+	}
+	if combo.Price < 0 {
+		return validationErrorf("цена комбо не может быть отрицательной")
+	}
+	if len(combo.Slots) == 0 {
+		return validationErrorf("комбо должно содержать хотя бы один слот")
+	}
+
+	var ids []string
+	for _, slot := range combo.Slots {
+		if strings.TrimSpace(slot.Name) == "" {
+			return validationErrorf("название слота обязательно")
+		}
+		if len(slot.Items) == 0 {
+			return validationErrorf("в слоте %q нет блюд для выбора", slot.Name)
+		}
+		seen := map[string]bool{}
+		defaults := 0
+		for _, item := range slot.Items {
+			if seen[item.MenuItemID] {
+				return validationErrorf("блюдо %s указано в слоте %q дважды", item.MenuItemID, slot.Name)
+			}
+			seen[item.MenuItemID] = true
+			if item.Surcharge < 0 {
+				return validationErrorf("доплата в слоте %q не может быть отрицательной", slot.Name)
+			}
+			if item.IsDefault {
+				defaults++
+			}
+			ids = append(ids, item.MenuItemID)
+		}
+		if defaults > 1 {
+			return validationErrorf("в слоте %q может быть только одно блюдо по умолчанию", slot.Name)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.MenuItem{}).Where("id IN ?", uniqueStrings(ids)).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(uniqueStrings(ids)) {
+		return validationErrorf("в слотах указаны несуществующие блюда")
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Сохранить комбо; слоты и блюда в них заменяются целиком
+func SaveCombo(db *gorm.DB, combo *models.Combo) error {
+	if err := ValidateCombo(db, combo); err != nil {
+		return err
+	}
+	slots := combo.Slots
+	return db.Transaction(func(tx *gorm.DB) error {
+		if combo.ID != "" {
+			var slotIDs []string
+			if err := tx.Model(&models.ComboSlot{}).Where("combo_id = ?", combo.ID).Pluck("id", &slotIDs).Error; err != nil {
+				return err
+			}
+			if len(slotIDs) > 0 {
+				if err := tx.Where("slot_id IN ?", slotIDs).Delete(&models.ComboSlotItem{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("id IN ?", slotIDs).Delete(&models.ComboSlot{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		combo.Slots = nil
+		if err := tx.Omit("Slots").Save(combo).Error; err != nil {
+			return err
+		}
+		for i := range slots {
+			slots[i].ID = ""
+			slots[i].ComboID = combo.ID
+			if slots[i].SortOrder == 0 {
+				slots[i].SortOrder = i
+			}
+			for j := range slots[i].Items {
+				slots[i].Items[j].ID = ""
+				if slots[i].Items[j].SortOrder == 0 {
+					slots[i].Items[j].SortOrder = j
+				}
+			}
+		}
+		if err := tx.Create(&slots).Error; err != nil {
+			return err
+		}
+		combo.Slots = slots
+		return nil
+	})
+}
+
+// Себестоимость блюд по действующим калькуляциям и текущим ценам склада;
+// для блюд без калькуляции — сохранённая себестоимость
+func componentCosts(db *gorm.DB, menuItemIDs []string) (map[string]models.Money, error) {
+	costs := make(map[string]models.Money, len(menuItemIDs))
+	if len(menuItemIDs) == 0 {
+		return costs, nil
+	}
+	var items []models.MenuItem
+	if err := db.Select("id", "cost_price").Where("id IN ?", menuItemIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		costs[item.ID] = item.CostPrice
+	}
+
+	calcs, err := GetActiveCalculations(db, menuItemIDs)
+	if err != nil {
+		return nil, err
+	}
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return nil, err
+	}
+	for id, calc := range calcs {
+		costs[id], _ = CalculateDishCost(calc, products)
+	}
+	return costs, nil
+}
+
+// Блюдо слота, выбираемое по умолчанию: отмеченное или первое
+func defaultSlotItem(slot models.ComboSlot) models.ComboSlotItem {
+	for _, item := range slot.Items {
+		if item.IsDefault {
+			return item
+		}
+	}
+	return slot.Items[0]
+}
+
+// 🍱 Заполнить названия блюд, доступность в точке, себестоимость и маржу комбо
+func AnnotateCombos(db *gorm.DB, locationID string, combos []models.Combo) error {
+	var ids []string
+	for _, combo := range combos {
+		for _, slot := range combo.Slots {
+			for _, item := range slot.Items {
+				ids = append(ids, item.MenuItemID)
+			}
+		}
+	}
+	ids = uniqueStrings(ids)
+
+	var dishes []models.MenuItem
+	if len(ids) > 0 {
+		if err := db.Select("id", "name").Where("id IN ?", ids).Find(&dishes).Error; err != nil {
+			return err
+		}
+	}
+	names := make(map[string]string, len(dishes))
+	for _, d := range dishes {
+		names[d.ID] = d.Name
+	}
+	costs, err := componentCosts(db, ids)
+	if err != nil {
+		return err
+	}
+	reasons, err := UnavailableReasonsAt(db, locationID, ids)
+	if err != nil {
+		return err
+	}
+
+	for ci := range combos {
+		combo := &combos[ci]
+		combo.CostPrice, combo.CostMin, combo.CostMax = 0, 0, 0
+		var surcharge models.Money
+		for si := range combo.Slots {
+			slot := &combo.Slots[si]
+			if len(slot.Items) == 0 {
+				continue
+			}
+			available := false
+			min, max := costs[slot.Items[0].MenuItemID], costs[slot.Items[0].MenuItemID]
+			for ii := range slot.Items {
+				item := &slot.Items[ii]
+				item.Name = names[item.MenuItemID]
+				item.CostPrice = costs[item.MenuItemID]
+				_, blocked := reasons[item.MenuItemID]
+				item.Available = !blocked
+				available = available || item.Available
+				if item.CostPrice < min {
+					min = item.CostPrice
+				}
+				if item.CostPrice > max {
+					max = item.CostPrice
+				}
+			}
+			def := defaultSlotItem(*slot)
+			combo.CostPrice += costs[def.MenuItemID]
+			surcharge += def.Surcharge
+			combo.CostMin += min
+			combo.CostMax += max
+			if !available && !combo.Unavailable {
+				combo.Unavailable = true
+				combo.Reason = "нет доступных блюд в слоте «" + slot.Name + "»"
+			}
+		}
+		if !combo.Published && !combo.Unavailable {
+			combo.Unavailable = true
+			combo.Reason = "не опубликовано"
+		}
+		combo.Margin = combo.Price + surcharge - combo.CostPrice
+	}
+	return nil
+}
+
+// Проверить выбор блюд в слотах и посчитать цену комбо на сервере.
+// selection: ID слота → ID блюда; если слот не указан, берётся блюдо по умолчанию.
+func PriceComboLine(db *gorm.DB, locationID, comboID string, selection map[string]string) (*PricedComboLine, error) {
+	combo, err := GetCombo(db, comboID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, validationErrorf("комбо не найдено")
+		}
+		return nil, err
+	}
+	if !combo.Published {
+		return nil, validationErrorf("комбо %q сейчас недоступно для заказа", combo.Name)
+	}
+
+	line := &PricedComboLine{Combo: *combo, UnitPrice: combo.Price}
+	var chosen []models.ComboSlotItem
+	matched := 0
+	for _, slot := range combo.Slots {
+		id, ok := selection[slot.ID]
+		if ok {
+			matched++
+		}
+		var pick *models.ComboSlotItem
+		for i := range slot.Items {
+			if (ok && slot.Items[i].MenuItemID == id) || (!ok && slot.Items[i].IsDefault) {
+				pick = &slot.Items[i]
+				break
+			}
+		}
+		if pick == nil && !ok && len(slot.Items) == 1 {
+			pick = &slot.Items[0]
+		}
+		if pick == nil {
+			if ok {
+				return nil, validationErrorf("блюдо %s нельзя выбрать в слоте %q", id, slot.Name)
+			}
+			return nil, validationErrorf("в слоте %q нужно выбрать блюдо", slot.Name)
+		}
+		chosen = append(chosen, *pick)
+		line.Items = append(line.Items, models.SelectedComboItem{
+			SlotID:     slot.ID,
+			SlotName:   slot.Name,
+			MenuItemID: pick.MenuItemID,
+			Surcharge:  pick.Surcharge,
+		})
+	}
+	if matched != len(selection) {
+		return nil, validationErrorf("выбраны слоты, которые не относятся к комбо %q", combo.Name)
+	}
+
+	ids := make([]string, 0, len(chosen))
+	for _, item := range chosen {
+		ids = append(ids, item.MenuItemID)
+	}
+	var dishes []models.MenuItem
+	if err := db.Select("id", "name", "price").Where("id IN ?", ids).Find(&dishes).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.MenuItem, len(dishes))
+	for _, d := range dishes {
+		byID[d.ID] = d
+	}
+	reasons, err := UnavailableReasonsAt(db, locationID, ids)
+	if err != nil {
+		return nil, err
+	}
+	locationMenu, err := LoadLocationMenu(db, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(line.Items))
+	for i := range line.Items {
+		item := &line.Items[i]
+		dish := byID[item.MenuItemID]
+		item.Name = dish.Name
+		item.BasePrice = locationMenu.Price(dish.ID, dish.Price)
+		if reason, ok := reasons[item.MenuItemID]; ok {
+			return nil, validationErrorf("блюдо %q в комбо сейчас недоступно: %s", item.Name, reason)
+		}
+		line.UnitPrice += item.Surcharge
+		keys = append(keys, item.SlotID+":"+item.MenuItemID)
+	}
+	sort.Strings(keys)
+	line.Key = strings.Join(keys, ",")
+	return line, nil
+}
+
+// Разложить цену позиции комбо (с доплатами) по блюдам для строк заказа: цена набора делится
+// пропорционально отдельной цене блюд, доплата достаётся выбранному блюду, остаток от округления — последнему.
+// Сумма долей всегда равна цене позиции.
+func SplitComboPrice(price models.Money, items []models.SelectedComboItem) []models.Money {
+	shares := make([]models.Money, len(items))
+	if len(items) == 0 {
+		return shares
+	}
+	var base models.Money
+	for _, item := range items {
+		base += item.BasePrice
+		price -= item.Surcharge
+	}
+	var allocated models.Money
+	for i, item := range items {
+		switch {
+		case i == len(items)-1:
+			shares[i] = price - allocated
+		case base > 0:
+			shares[i] = models.Money(int64(price) * int64(item.BasePrice) / int64(base))
+		default:
+			shares[i] = price / models.Money(len(items))
+		}
+		allocated += shares[i]
+	}
+	for i, item := range items {
+		shares[i] += item.Surcharge
+	}
+	return shares
+}
+
+// Причины, по которым позиции корзины нельзя заказать сейчас, по ID позиции.
+// Для комбо проверяются само комбо и каждое выбранное блюдо.
+func CartUnavailableReasons(db *gorm.DB, locationID string, items []models.CartItem) (map[uint]string, error) {
+	var dishIDs, comboIDs []string
+	for _, item := range items {
+		if item.ComboID == nil {
+			dishIDs = append(dishIDs, item.MenuItemID)
+			continue
+		}
+		comboIDs = append(comboIDs, *item.ComboID)
+		for _, component := range item.ComboItems {
+			dishIDs = append(dishIDs, component.MenuItemID)
+		}
+	}
+	reasons, err := UnavailableReasonsAt(db, locationID, uniqueStrings(dishIDs))
+	if err != nil {
+		return nil, err
+	}
+	published := map[string]bool{}
+	if len(comboIDs) > 0 {
+		var combos []models.Combo
+		if err := db.Select("id", "published").Where("id IN ?", uniqueStrings(comboIDs)).Find(&combos).Error; err != nil {
+			return nil, err
+		}
+		for _, combo := range combos {
+			published[combo.ID] = combo.Published
+		}
+	}
+
+	result := map[uint]string{}
+	for _, item := range items {
+		if item.ComboID == nil {
+			if reason, ok := reasons[item.MenuItemID]; ok {
+				result[item.ID] = reason
+			}
+			continue
+		}
+		if isPublished, ok := published[*item.ComboID]; !ok {
+			result[item.ID] = "комбо не найдено"
+			continue
+		} else if !isPublished {
+			result[item.ID] = "комбо не опубликовано"
+			continue
+		}
+		for _, component := range item.ComboItems {
+			if reason, ok := reasons[component.MenuItemID]; ok {
+				result[item.ID] = component.Name + ": " + reason
+				break
+			}
+		}
+	}
+	return result, nil
+}
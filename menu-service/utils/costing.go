@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Себестоимость блюда по калькуляции и текущим ценам склада.
+// AmountGrams — вес брутто, поэтому цена за кг применяется к нему без поправки на отходы.
+// Если продукт не найден на складе, берётся цена, сохранённая в калькуляции.
+func CalculateDishCost(calc models.Calculation, products productIndex) (models.Money, []string) {
+	var total models.Money
+	var missing []string
+	for _, ing := range calc.Ingredients {
+		pricePerKg := ing.PricePerKg
+		if product, ok := products.find(ing); ok {
+			pricePerKg = product.PricePerKg
+		} else {
+			missing = append(missing, ing.ProductName)
+		}
+		total += pricePerKg.ForGrams(ing.AmountGrams)
+	}
+	return total, missing
+}
+
+// Себестоимость блюда по действующей калькуляции; ok = false — калькуляции у блюда нет
+func ActiveDishCost(db *gorm.DB, menuItemID string) (cost models.Money, ok bool, err error) {
+	calcs, err := GetActiveCalculations(db, []string{menuItemID})
+	if err != nil {
+		return 0, false, err
+	}
+	calc, ok := calcs[menuItemID]
+	if !ok {
+		return 0, false, nil
+	}
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return 0, false, err
+	}
+	cost, _ = CalculateDishCost(calc, products)
+	return cost, true, nil
+}
+
+// Блюда, в действующей калькуляции которых используется продукт (по ID склада или названию)
+func MenuItemIDsUsingProduct(db *gorm.DB, inventoryItemID string, productNames ...string) ([]string, error) {
+	lower := make([]string, 0, len(productNames))
+	for _, name := range productNames {
+		lower = append(lower, productKey(name))
+	}
+
+	var ids []string
+	err := db.Raw(`
+		SELECT DISTINCT latest.menu_item_id
+		FROM (`+activeCalculationsSQL+`) latest
+		JOIN calculation_ingredients ci ON ci.calculation_id = latest.id
+		WHERE ci.inventory_item_id = ? OR lower(ci.product_name) IN ?
+	`, inventoryItemID, lower).Scan(&ids).Error
+	return ids, err
+}
+
+// Пересчитать себестоимость и маржу блюд по калькуляциям.
+// menuItemIDs = nil — пересчитать все блюда с калькуляцией.
+// Возвращает только блюда, у которых себестоимость изменилась.
+func RecalculateDishCosts(db *gorm.DB, menuItemIDs []string) ([]models.DishCostChange, error) {
+	if menuItemIDs == nil {
+		if err := db.Model(&models.Calculation{}).Distinct().Pluck("menu_item_id", &menuItemIDs).Error; err != nil {
+			return nil, err
+		}
+	}
+	changes := []models.DishCostChange{}
+	if len(menuItemIDs) == 0 {
+		return changes, nil
+	}
+
+	calcs, err := GetActiveCalculations(db, menuItemIDs)
+	if err != nil {
+		return nil, err
+	}
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.MenuItem
+	if err := db.Where("id IN ?", menuItemIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			calc, ok := calcs[item.ID]
+			if !ok {
+				continue
+			}
+			cost, missing := CalculateDishCost(calc, products)
+			if cost == item.CostPrice {
+				continue
+			}
+			margin := item.Price - cost
+			if err := tx.Model(&models.MenuItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+				"cost_price": cost,
+				"margin":     margin,
+			}).Error; err != nil {
+				return err
+			}
+			if err := RecordPriceChange(tx, priceChangeOf(item, item.Price, cost, models.PriceSourceCalculation, "")); err != nil {
+				return err
+			}
+			changes = append(changes, models.DishCostChange{
+				MenuItemID: item.ID,
+				Name:       item.Name,
+				OldCost:    item.CostPrice,
+				NewCost:    cost,
+				OldMargin:  item.Margin,
+				NewMargin:  margin,
+				Delta:      cost - item.CostPrice,
+				Missing:    missing,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
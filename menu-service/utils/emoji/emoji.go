@@ -1,41 +1,14 @@
 package emoji
 
-import "strings"
-
-// 1. Ключевые слова → Категории
-var keywordToCategory = map[string]string{
-	// Соусы
-	"соус": "соусы", "соевый": "соусы", "майонез": "соусы", "цезарь": "соусы",
-	"тар-тар": "соусы", "песто": "соусы", "чили": "соусы", "устричный": "соусы",
-	"тартар": "соусы", "кунжутный": "соусы",
-
-	// Овощи
-	"овощ": "овощи", "помидор": "овощи", "томат": "овощи", "огурец": "овощи", "перец": "овощи",
-	"болгарский": "овощи", "морковь": "овощи", "лук": "овощи", "чеснок": "овощи",
-	"брокколи": "овощи", "капуста": "овощи", "цветная": "овощи", "брюссельская": "овощи",
-	"свекла": "овощи", "редис": "овощи", "картофель": "овощи", "батат": "овощи",
-	"кукуруза": "овощи", "баклажан": "овощи", "кабачок": "овощи", "цуккини": "овощи",
-	"авокадо": "овощи",
-
-	// Сыры
-	"сыр": "сыры", "моцарелла": "сыры", "пармезан": "сыры", "гауда": "сыры", "бри": "сыры",
-	"камамбер": "сыры", "чеддер": "сыры", "фета": "сыры", "горгонзола": "сыры", "тофу": "сыры",
-
-	// Рыба
-	"рыба": "рыба", "лосось": "рыба", "семга": "рыба", "тунец": "рыба", "форель": "рыба",
-	"макрель": "рыба", "судак": "рыба", "минтай": "рыба", "дорадо": "рыба", "анчоус": "рыба",
-
-	// Рис
-	"рис": "рис",
-
-	// Суши
-	"суши": "суши",
-
-	// Зелень
-	"зелень": "зелень", "петрушка": "зелень", "укроп": "зелень", "рукола": "зелень", "кинза": "зелень",
-}
+import (
+	"math"
+	"sort"
+)
+
+// Категория для названий, в которых не нашлось ни одного ключевого слова
+const Other = "прочее"
 
-// 2. Категория → Emoji
+// Категория → Emoji
 var categoryToEmoji = map[string]string{
 	"сыры":   "🧀",
 	"рыба":   "🐟",
@@ -46,25 +19,179 @@ var categoryToEmoji = map[string]string{
 	"зелень": "🥬",
 }
 
-// 3. Генерация emoji по имени
-func GenerateEmoji(name string) string {
-	category := GenerateCategory(name)
-	if emoji, ok := categoryToEmoji[category]; ok {
-		return emoji
+const defaultEmoji = "🍽️"
+
+// Вклад прилагательного в уверенность относительно существительного
+const modifierWeight = 0.5
+
+// 🏷 Результат классификации продукта
+type Classification struct {
+	Category   string      `json:"category"`
+	Emoji      string      `json:"emoji"`
+	Confidence float64     `json:"confidence"`        // 0 — ничего не найдено, 1 — однозначно
+	Keyword    string      `json:"keyword,omitempty"` // ключевое слово, решившее категорию
+	Ambiguous  bool        `json:"ambiguous"`         // в названии есть слова нескольких категорий
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// Категория, слова которой тоже нашлись в названии
+type Candidate struct {
+	Category string `json:"category"`
+	Keyword  string `json:"keyword"`
+}
+
+// Ключевое слово, разобранное на основы
+type compiledKeyword struct {
+	keyword
+	stems []string
+}
+
+// Ключевые слова по основе первого слова фразы
+var index = buildIndex()
+
+func buildIndex() map[string][]compiledKeyword {
+	result := map[string][]compiledKeyword{}
+	for _, kw := range keywords {
+		s := stems(kw.phrase)
+		if len(s) == 0 {
+			continue
+		}
+		result[s[0]] = append(result[s[0]], compiledKeyword{keyword: kw, stems: s})
 	}
-	return "🍽️"
+	return result
 }
 
-// 4. Генерация категории по имени
-func GenerateCategory(name string) string {
-	n := strings.ToLower(name)
-	for keyword, category := range keywordToCategory {
-		if strings.Contains(n, keyword) {
-			return category
+// Найденная в названии фраза: позиция и длина в словах
+type match struct {
+	compiledKeyword
+	pos int
+}
+
+func (m match) end() int { return m.pos + len(m.stems) }
+
+func (m match) weight() float64 {
+	w := float64(len(m.stems))
+	if m.modifier {
+		w *= modifierWeight
+	}
+	return w
+}
+
+// Какая находка важнее: длинная фраза, затем существительное, затем главное слово названия.
+// В русских складских названиях главное слово обычно первое («соус сырный», «рис для суши»),
+// в английских — последнее («cheese sauce», «sushi rice»): headLast.
+func (m match) before(o match, headLast bool) bool {
+	if len(m.stems) != len(o.stems) {
+		return len(m.stems) > len(o.stems)
+	}
+	if m.modifier != o.modifier {
+		return !m.modifier
+	}
+	if m.pos != o.pos {
+		return (m.pos < o.pos) != headLast
+	}
+	if len(m.phrase) != len(o.phrase) {
+		return len(m.phrase) > len(o.phrase)
+	}
+	return m.category < o.category
+}
+
+// 🏷 Определить категорию продукта по названию. Результат не зависит от порядка ключевых слов:
+// побеждает самая длинная и конкретная фраза, совпадающая с целыми словами названия.
+func Classify(name string) Classification {
+	tokens := stems(name)
+	var found []match
+	for i, token := range tokens {
+		for _, kw := range index[token] {
+			if i+len(kw.stems) <= len(tokens) && equal(tokens[i:i+len(kw.stems)], kw.stems) {
+				found = append(found, match{compiledKeyword: kw, pos: i})
+			}
 		}
 	}
-	return "прочее"
+	if len(found) == 0 {
+		return Classification{Category: Other, Emoji: defaultEmoji}
+	}
+	headLast := !isCyrillic(name)
+	sort.Slice(found, func(i, j int) bool { return found[i].before(found[j], headLast) })
+
+	// Слова, вошедшие в более важную фразу, повторно не учитываются:
+	// «перец чили» — это овощ, а не ещё и соус чили
+	used := make([]bool, len(tokens))
+	best := map[string]match{}
+	var order []string
+	for _, m := range found {
+		overlaps := false
+		for i := m.pos; i < m.end(); i++ {
+			overlaps = overlaps || used[i]
+		}
+		if overlaps {
+			continue
+		}
+		for i := m.pos; i < m.end(); i++ {
+			used[i] = true
+		}
+		if _, ok := best[m.category]; !ok {
+			best[m.category] = m
+			order = append(order, m.category)
+		}
+	}
+
+	winner := best[order[0]]
+	total := 0.0
+	for _, category := range order {
+		total += best[category].weight()
+	}
+	confidence := winner.weight() / total
+	if winner.modifier {
+		// Одно прилагательное без предмета («сырный») — догадка
+		confidence *= modifierWeight
+	}
+
+	result := Classification{
+		Category:   winner.category,
+		Emoji:      emojiFor(winner.category),
+		Confidence: math.Round(confidence*100) / 100,
+		Keyword:    winner.phrase,
+		Ambiguous:  len(order) > 1,
+	}
+	for _, category := range order[1:] {
+		result.Candidates = append(result.Candidates, Candidate{Category: category, Keyword: best[category].phrase})
+	}
+	return result
+}
+
+func equal(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func emojiFor(category string) string {
+	if emoji, ok := categoryToEmoji[category]; ok {
+		return emoji
+	}
+	return defaultEmoji
 }
 
+// Генерация emoji по имени
+func GenerateEmoji(name string) string {
+	return Classify(name).Emoji
+}
 
+// Генерация категории по имени
+func GenerateCategory(name string) string {
+	return Classify(name).Category
+}
 
+// Все категории, которые может вернуть GenerateCategory
+func Categories() []string {
+	categories := make([]string, 0, len(categoryToEmoji)+1)
+	for category := range categoryToEmoji {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return append(categories, Other)
+}
@@ -0,0 +1,89 @@
+package emoji
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name       string
+		category   string
+		confidence float64
+		ambiguous  bool
+	}{
+		// Несколько категорий в названии: побеждает существительное, а не прилагательное
+		{name: "соус сырный", category: "соусы", confidence: 0.67, ambiguous: true},
+		{name: "Соусы сырные", category: "соусы", confidence: 0.67, ambiguous: true},
+		{name: "Рис для суши", category: "рис", confidence: 0.5, ambiguous: true},
+
+		// Совпадают только целые слова: «лук» не находится в «лукум»
+		{name: "лукум", category: Other, confidence: 0},
+		{name: "лук", category: "овощи", confidence: 1},
+
+		// Фраза важнее отдельных слов: «перец чили» — овощ, а не соус чили
+		{name: "Перец чили", category: "овощи", confidence: 1},
+		{name: "перцы чили", category: "овощи", confidence: 1},
+		{name: "перца чили", category: "овощи", confidence: 1},
+		{name: "Лук зелёный", category: "зелень", confidence: 1},
+
+		// Падежи, число и регистр
+		{name: "огурец", category: "овощи", confidence: 1},
+		{name: "Огурцы", category: "овощи", confidence: 1},
+		{name: "помидоры", category: "овощи", confidence: 1},
+		{name: "ЛОСОСЯ", category: "рыба", confidence: 1},
+		{name: "Сыры", category: "сыры", confidence: 1},
+
+		// «сырая» — не сыр
+		{name: "Говядина сырая", category: Other, confidence: 0},
+
+		// Английские названия и множественное число
+		{name: "tomatoes", category: "овощи", confidence: 1},
+		{name: "Onions", category: "овощи", confidence: 1},
+		{name: "anchovies", category: "рыба", confidence: 1},
+		{name: "Green onion", category: "зелень", confidence: 1},
+
+		// В английских названиях главное слово последнее
+		{name: "Cheese sauce", category: "соусы", confidence: 0.5, ambiguous: true},
+		{name: "Fish sauce", category: "соусы", confidence: 0.5, ambiguous: true},
+		{name: "sushi rice", category: "рис", confidence: 0.5, ambiguous: true},
+		{name: "Chili pepper", category: "овощи", confidence: 1},
+	}
+	for _, tt := range tests {
+		got := Classify(tt.name)
+		if got.Category != tt.category || got.Confidence != tt.confidence || got.Ambiguous != tt.ambiguous {
+			t.Errorf("Classify(%q) = %s %.2f ambiguous=%v, want %s %.2f ambiguous=%v",
+				tt.name, got.Category, got.Confidence, got.Ambiguous, tt.category, tt.confidence, tt.ambiguous)
+		}
+		if got.Emoji != emojiFor(tt.category) {
+			t.Errorf("Classify(%q).Emoji = %q, want %q", tt.name, got.Emoji, emojiFor(tt.category))
+		}
+		if category := GenerateCategory(tt.name); category != tt.category {
+			t.Errorf("GenerateCategory(%q) = %q, want %q", tt.name, category, tt.category)
+		}
+	}
+}
+
+func TestClassifyCandidates(t *testing.T) {
+	got := Classify("соус сырный")
+	if got.Keyword != "соус" {
+		t.Errorf("Keyword = %q, want %q", got.Keyword, "соус")
+	}
+	if len(got.Candidates) != 1 || got.Candidates[0].Category != "сыры" {
+		t.Errorf("Candidates = %v, want [сыры]", got.Candidates)
+	}
+}
+
+// Результат не зависит от обхода map: повторные вызовы дают одно и то же
+func TestClassifyDeterministic(t *testing.T) {
+	tests := map[string]string{
+		"соус сырный":     "соусы",
+		"Рис для суши":    "рис",
+		"Cheese sauce":    "соусы",
+		"перец чили соус": "овощи",
+	}
+	for name, want := range tests {
+		for i := 0; i < 100; i++ {
+			if got := GenerateCategory(name); got != want {
+				t.Fatalf("GenerateCategory(%q) = %q на вызове %d, want %q", name, got, i, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package emoji
+
+// Ключевое слово или фраза категории. Фраза совпадает только с целыми словами
+// подряд, сравниваются основы, поэтому падежи и число указывать не нужно.
+// modifier — прилагательное («сырный», «соевый»): уступает любому существительному.
+type keyword struct {
+	phrase   string
+	category string
+	modifier bool
+}
+
+// Порядок не влияет на результат — он определяется длиной фразы, видом слова и позицией в названии
+var keywords = []keyword{
+	// Соусы
+	{phrase: "соус", category: "соусы"},
+	{phrase: "майонез", category: "соусы"},
+	{phrase: "цезарь", category: "соусы"},
+	{phrase: "тар тар", category: "соусы"},
+	{phrase: "тартар", category: "соусы"},
+	{phrase: "песто", category: "соусы"},
+	{phrase: "чили", category: "соусы"},
+	{phrase: "соевый", category: "соусы", modifier: true},
+	{phrase: "устричный", category: "соусы", modifier: true},
+	{phrase: "кунжутный", category: "соусы", modifier: true},
+	{phrase: "sauce", category: "соусы"},
+	{phrase: "mayonnaise", category: "соусы"},
+	{phrase: "mayo", category: "соусы"},
+	{phrase: "caesar", category: "соусы"},
+	{phrase: "tartar", category: "соусы"},
+	{phrase: "tartare", category: "соусы"},
+	{phrase: "pesto", category: "соусы"},
+	{phrase: "chili", category: "соусы"},
+	{phrase: "soy", category: "соусы", modifier: true},
+	{phrase: "oyster", category: "соусы", modifier: true},
+	{phrase: "sesame", category: "соусы", modifier: true},
+
+	// Овощи
+	{phrase: "овощ", category: "овощи"},
+	{phrase: "помидор", category: "овощи"},
+	{phrase: "томат", category: "овощи"},
+	{phrase: "огурец", category: "овощи"},
+	{phrase: "огурц", category: "овощи"}, // огурцы, огурца — беглая гласная
+	{phrase: "перец", category: "овощи"},
+	{phrase: "перц", category: "овощи"},
+	{phrase: "перец чили", category: "овощи"},
+	{phrase: "перц чили", category: "овощи"}, // перцы чили, перца чили
+	{phrase: "морковь", category: "овощи"},
+	{phrase: "лук", category: "овощи"},
+	{phrase: "чеснок", category: "овощи"},
+	{phrase: "брокколи", category: "овощи"},
+	{phrase: "капуста", category: "овощи"},
+	{phrase: "свекла", category: "овощи"},
+	{phrase: "редис", category: "овощи"},
+	{phrase: "картофель", category: "овощи"},
+	{phrase: "батат", category: "овощи"},
+	{phrase: "кукуруза", category: "овощи"},
+	{phrase: "баклажан", category: "овощи"},
+	{phrase: "кабачок", category: "овощи"},
+	{phrase: "кабачк", category: "овощи"},
+	{phrase: "цуккини", category: "овощи"},
+	{phrase: "авокадо", category: "овощи"},
+	{phrase: "овощной", category: "овощи", modifier: true},
+	{phrase: "болгарский", category: "овощи", modifier: true},
+	{phrase: "цветная", category: "овощи", modifier: true},
+	{phrase: "брюссельская", category: "овощи", modifier: true},
+	{phrase: "vegetable", category: "овощи"},
+	{phrase: "tomato", category: "овощи"},
+	{phrase: "cucumber", category: "овощи"},
+	{phrase: "pepper", category: "овощи"},
+	{phrase: "bell pepper", category: "овощи"},
+	{phrase: "green pepper", category: "овощи"},
+	{phrase: "chili pepper", category: "овощи"},
+	{phrase: "carrot", category: "овощи"},
+	{phrase: "onion", category: "овощи"},
+	{phrase: "garlic", category: "овощи"},
+	{phrase: "broccoli", category: "овощи"},
+	{phrase: "cabbage", category: "овощи"},
+	{phrase: "cauliflower", category: "овощи"},
+	{phrase: "brussels sprout", category: "овощи"},
+	{phrase: "beet", category: "овощи"},
+	{phrase: "beetroot", category: "овощи"},
+	{phrase: "radish", category: "овощи"},
+	{phrase: "potato", category: "овощи"},
+	{phrase: "sweet potato", category: "овощи"},
+	{phrase: "corn", category: "овощи"},
+	{phrase: "eggplant", category: "овощи"},
+	{phrase: "aubergine", category: "овощи"},
+	{phrase: "zucchini", category: "овощи"},
+	{phrase: "courgette", category: "овощи"},
+	{phrase: "avocado", category: "овощи"},
+
+	// Сыры
+	{phrase: "сыр", category: "сыры"},
+	{phrase: "моцарелла", category: "сыры"},
+	{phrase: "пармезан", category: "сыры"},
+	{phrase: "гауда", category: "сыры"},
+	{phrase: "бри", category: "сыры"},
+	{phrase: "камамбер", category: "сыры"},
+	{phrase: "чеддер", category: "сыры"},
+	{phrase: "фета", category: "сыры"},
+	{phrase: "горгонзола", category: "сыры"},
+	{phrase: "тофу", category: "сыры"},
+	{phrase: "сырный", category: "сыры", modifier: true},
+	{phrase: "cheese", category: "сыры"},
+	{phrase: "mozzarella", category: "сыры"},
+	{phrase: "parmesan", category: "сыры"},
+	{phrase: "gouda", category: "сыры"},
+	{phrase: "brie", category: "сыры"},
+	{phrase: "camembert", category: "сыры"},
+	{phrase: "cheddar", category: "сыры"},
+	{phrase: "feta", category: "сыры"},
+	{phrase: "gorgonzola", category: "сыры"},
+	{phrase: "tofu", category: "сыры"},
+
+	// Рыба
+	{phrase: "рыба", category: "рыба"},
+	{phrase: "лосось", category: "рыба"},
+	{phrase: "семга", category: "рыба"},
+	{phrase: "тунец", category: "рыба"},
+	{phrase: "тунц", category: "рыба"},
+	{phrase: "форель", category: "рыба"},
+	{phrase: "макрель", category: "рыба"},
+	{phrase: "судак", category: "рыба"},
+	{phrase: "минтай", category: "рыба"},
+	{phrase: "дорадо", category: "рыба"},
+	{phrase: "анчоус", category: "рыба"},
+	{phrase: "рыбный", category: "рыба", modifier: true},
+	{phrase: "fish", category: "рыба"},
+	{phrase: "salmon", category: "рыба"},
+	{phrase: "tuna", category: "рыба"},
+	{phrase: "trout", category: "рыба"},
+	{phrase: "mackerel", category: "рыба"},
+	{phrase: "zander", category: "рыба"},
+	{phrase: "pollock", category: "рыба"},
+	{phrase: "dorado", category: "рыба"},
+	{phrase: "anchovy", category: "рыба"},
+
+	// Рис
+	{phrase: "рис", category: "рис"},
+	{phrase: "rice", category: "рис"},
+
+	// Суши
+	{phrase: "суши", category: "суши"},
+	{phrase: "sushi", category: "суши"},
+
+	// Зелень
+	{phrase: "зелень", category: "зелень"},
+	{phrase: "зеленый лук", category: "зелень"},
+	{phrase: "лук зеленый", category: "зелень"},
+	{phrase: "петрушка", category: "зелень"},
+	{phrase: "укроп", category: "зелень"},
+	{phrase: "рукола", category: "зелень"},
+	{phrase: "кинза", category: "зелень"},
+	{phrase: "greens", category: "зелень"},
+	{phrase: "green onion", category: "зелень"},
+	{phrase: "parsley", category: "зелень"},
+	{phrase: "dill", category: "зелень"},
+	{phrase: "arugula", category: "зелень"},
+	{phrase: "rocket", category: "зелень"},
+	{phrase: "cilantro", category: "зелень"},
+	{phrase: "coriander", category: "зелень"},
+}
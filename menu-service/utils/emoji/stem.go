@@ -0,0 +1,106 @@
+package emoji
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Основа слова короче трёх букв не обрезается: «бри», «лук», «рис» остаются как есть
+const minStemLength = 3
+
+// Окончания русских существительных и прилагательных, от длинных к коротким
+var russianEndings = []string{
+	"ыми", "ими", "ого", "его", "ому", "ему", "ами", "ями",
+	"ая", "яя", "ое", "ее", "ые", "ие", "ый", "ий", "ой", "ую", "юю", "ых", "их", "ым", "им",
+	"ов", "ев", "ей", "ом", "ем", "ам", "ям", "ах", "ях", "ью",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+}
+
+// Служебные слова не участвуют в поиске
+var ignoredWords = map[string]bool{
+	"для": true, "с": true, "со": true, "в": true, "во": true, "из": true, "и": true, "на": true, "без": true,
+	"with": true, "and": true, "for": true, "in": true, "of": true,
+}
+
+// Формы, основа которых совпала бы с ключевым словом («сырая» → «сыр», «зелёный» → «зелень»):
+// они не обрезаются, а приводятся к одной словарной форме
+var canonicalForms = formsOf(map[string][]string{
+	"сырой":   {"сырой", "сырая", "сырое", "сырые", "сырого", "сырых", "сырым", "сырыми", "сырую"},
+	"зеленый": {"зеленый", "зеленая", "зеленое", "зеленые", "зеленого", "зеленых", "зеленым", "зелеными", "зеленую"},
+})
+
+func formsOf(groups map[string][]string) map[string]string {
+	result := map[string]string{}
+	for canonical, forms := range groups {
+		for _, form := range forms {
+			result[form] = canonical
+		}
+	}
+	return result
+}
+
+// Слова названия в нижнем регистре: разделители — всё, кроме букв и цифр; ё → е
+func words(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Основы значимых слов названия
+func stems(s string) []string {
+	var result []string
+	for _, w := range words(s) {
+		if ignoredWords[w] {
+			continue
+		}
+		if canonical, ok := canonicalForms[w]; ok {
+			result = append(result, canonical)
+			continue
+		}
+		result = append(result, stem(w))
+	}
+	return result
+}
+
+// Основа слова: у кириллицы отрезается падежное окончание, у латиницы — множественное число
+func stem(w string) string {
+	if isCyrillic(w) {
+		return stemRussian(w)
+	}
+	return stemEnglish(w)
+}
+
+func isCyrillic(w string) bool {
+	for _, r := range w {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func stemRussian(w string) string {
+	for _, ending := range russianEndings {
+		if strings.HasSuffix(w, ending) && utf8.RuneCountInString(w)-utf8.RuneCountInString(ending) >= minStemLength {
+			return strings.TrimSuffix(w, ending)
+		}
+	}
+	return w
+}
+
+func stemEnglish(w string) string {
+	switch {
+	case len(w) <= minStemLength:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"), strings.HasSuffix(w, "zes"):
+		return strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
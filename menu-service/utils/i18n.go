@@ -0,0 +1,292 @@
+package utils
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"monolith/menu-service/models"
+	"monolith/menu-service/utils/emoji"
+
+	"gorm.io/gorm"
+)
+
+// Язык, на котором ведётся исходное меню
+const DefaultLanguage = "ru"
+
+// Поддерживаемые языки из MENU_LANGUAGES (по умолчанию ru,en,zh); задаются в Init
+var SupportedLanguages = []string{DefaultLanguage, "en", "zh"}
+
+func loadSupportedLanguages() []string {
+	raw := os.Getenv("MENU_LANGUAGES")
+	if raw == "" {
+		raw = "ru,en,zh"
+	}
+	langs := []string{DefaultLanguage}
+	for _, lang := range strings.Split(raw, ",") {
+		lang = normalizeLanguage(lang)
+		if lang != "" && lang != DefaultLanguage {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// "zh-CN" → "zh", "EN_us" → "en"
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// Поддерживается ли язык
+func IsSupportedLanguage(lang string) bool {
+	for _, l := range SupportedLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Выбор языка: ?lang= важнее Accept-Language; неизвестный язык — исходный русский
+func NegotiateLanguage(queryLang, acceptLanguage string) string {
+	if lang := normalizeLanguage(queryLang); IsSupportedLanguage(lang) {
+		return lang
+	}
+
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for i, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := normalizeLanguage(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		// при равном q выигрывает язык, указанный раньше
+		candidates = append(candidates, candidate{lang: lang, q: q - float64(i)*1e-6})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.q > 0 && IsSupportedLanguage(c.lang) {
+			return c.lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// Подставить переводы в блюда (если перевода нет — остаётся оригинал)
+func TranslateMenuItems(db *gorm.DB, lang string, items []models.MenuItem) error {
+	if lang == DefaultLanguage || len(items) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	translations, err := menuItemTranslations(db, lang, ids)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if t, ok := translations[items[i].ID]; ok {
+			items[i].Name = t.Name
+			if t.Description != "" {
+				items[i].Description = t.Description
+			}
+		}
+	}
+	return nil
+}
+
+// Подставить переводы в блюда с категорией
+func TranslateMenuItemsWithCategory(db *gorm.DB, lang string, items []models.MenuItemWithCategory) error {
+	if lang == DefaultLanguage || len(items) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(items))
+	categoryIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+		if item.CategoryID != "" {
+			categoryIDs = append(categoryIDs, item.CategoryID)
+		}
+	}
+	translations, err := menuItemTranslations(db, lang, ids)
+	if err != nil {
+		return err
+	}
+	categoryNames, err := categoryTranslations(db, lang, categoryIDs)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if t, ok := translations[items[i].ID]; ok {
+			items[i].Name = t.Name
+			if t.Description != "" {
+				items[i].Description = t.Description
+			}
+		}
+		if t, ok := categoryNames[items[i].CategoryID]; ok {
+			items[i].CategoryName = t.Name
+		}
+	}
+	return nil
+}
+
+// Подставить переводы в категории меню
+func TranslateCategories(db *gorm.DB, lang string, categories []models.Category) error {
+	if lang == DefaultLanguage || len(categories) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(categories))
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+	translations, err := categoryTranslations(db, lang, ids)
+	if err != nil {
+		return err
+	}
+	for i := range categories {
+		if t, ok := translations[categories[i].ID]; ok {
+			categories[i].Name = t.Name
+			if t.Description != "" {
+				categories[i].Description = t.Description
+			}
+		}
+	}
+	return nil
+}
+
+// Подписи складских категорий на выбранном языке (ключ Category не меняется)
+func TranslateInventoryCategories(db *gorm.DB, lang string, items []models.InventoryItem) error {
+	var translations []models.InventoryCategoryTranslation
+	if lang != DefaultLanguage {
+		if err := db.Where("lang = ?", lang).Find(&translations).Error; err != nil {
+			return err
+		}
+	}
+	names := make(map[string]string, len(translations))
+	for _, t := range translations {
+		names[t.Category] = t.Name
+	}
+	for i := range items {
+		if items[i].Category == nil {
+			continue
+		}
+		items[i].CategoryLabel = *items[i].Category
+		if name, ok := names[*items[i].Category]; ok {
+			items[i].CategoryLabel = name
+		}
+	}
+	return nil
+}
+
+func menuItemTranslations(db *gorm.DB, lang string, ids []string) (map[string]models.MenuItemTranslation, error) {
+	var rows []models.MenuItemTranslation
+	if err := db.Where("lang = ? AND menu_item_id IN ?", lang, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]models.MenuItemTranslation, len(rows))
+	for _, t := range rows {
+		result[t.MenuItemID] = t
+	}
+	return result, nil
+}
+
+func categoryTranslations(db *gorm.DB, lang string, ids []string) (map[string]models.CategoryTranslation, error) {
+	result := map[string]models.CategoryTranslation{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var rows []models.CategoryTranslation
+	if err := db.Where("lang = ? AND category_id IN ?", lang, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range rows {
+		result[t.CategoryID] = t
+	}
+	return result, nil
+}
+
+// 📊 Отчёт о полноте переводов по всем языкам, кроме исходного
+func TranslationCompletenessReport(db *gorm.DB) ([]models.TranslationCompleteness, error) {
+	var itemIDs, categoryIDs []string
+	if err := db.Model(&models.MenuItem{}).Pluck("id", &itemIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Category{}).Pluck("id", &categoryIDs).Error; err != nil {
+		return nil, err
+	}
+	inventoryCategories := emoji.Categories()
+
+	report := []models.TranslationCompleteness{}
+	for _, lang := range SupportedLanguages {
+		if lang == DefaultLanguage {
+			continue
+		}
+
+		var doneItems, doneCategories, doneInventory []string
+		if err := db.Model(&models.MenuItemTranslation{}).Where("lang = ?", lang).Pluck("menu_item_id", &doneItems).Error; err != nil {
+			return nil, err
+		}
+		if err := db.Model(&models.CategoryTranslation{}).Where("lang = ?", lang).Pluck("category_id", &doneCategories).Error; err != nil {
+			return nil, err
+		}
+		if err := db.Model(&models.InventoryCategoryTranslation{}).Where("lang = ?", lang).Pluck("category", &doneInventory).Error; err != nil {
+			return nil, err
+		}
+
+		r := models.TranslationCompleteness{
+			Lang:                lang,
+			MenuItemsTotal:      len(itemIDs),
+			CategoriesTotal:     len(categoryIDs),
+			InventoryTotal:      len(inventoryCategories),
+			MissingMenuItems:    missingKeys(itemIDs, doneItems),
+			MissingCategories:   missingKeys(categoryIDs, doneCategories),
+			MissingInventoryCat: missingKeys(inventoryCategories, doneInventory),
+		}
+		r.MenuItemsDone = r.MenuItemsTotal - len(r.MissingMenuItems)
+		r.CategoriesDone = r.CategoriesTotal - len(r.MissingCategories)
+		r.InventoryDone = r.InventoryTotal - len(r.MissingInventoryCat)
+
+		total := r.MenuItemsTotal + r.CategoriesTotal + r.InventoryTotal
+		if total > 0 {
+			done := r.MenuItemsDone + r.CategoriesDone + r.InventoryDone
+			r.Percent = math.Round(float64(done)/float64(total)*1000) / 10
+		}
+		report = append(report, r)
+	}
+	return report, nil
+}
+
+func missingKeys(all, done []string) []string {
+	have := make(map[string]bool, len(done))
+	for _, k := range done {
+		have[k] = true
+	}
+	missing := []string{}
+	for _, k := range all {
+		if !have[k] {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"sort"
+
 	"monolith/menu-service/models"
 	"monolith/menu-service/utils/emoji"
 
@@ -42,6 +44,13 @@ func UpdateInventoryItem(db *gorm.DB, id string, item *models.InventoryItem) err
 	existing.Available = item.Available
 	existing.Emoji = item.Emoji
 	existing.Category = item.Category
+	existing.KcalPer100g = item.KcalPer100g
+	existing.ProteinPer100g = item.ProteinPer100g
+	existing.FatPer100g = item.FatPer100g
+	existing.CarbsPer100g = item.CarbsPer100g
+	existing.Allergens = item.Allergens
+	existing.Vegetarian = item.Vegetarian
+	existing.Vegan = item.Vegan
 
 	return db.Save(&existing).Error
 }
@@ -51,6 +60,44 @@ func DeleteInventoryItem(db *gorm.DB, id string) error {
 	return db.Delete(&models.InventoryItem{}, "id = ?", id).Error
 }
 
-
-
-
+// 🏷 Проверить категории склада классификатором: неоднозначные названия и расхождения
+// с сохранённой категорией. Сначала — наименее уверенные.
+func ClassifyInventory(db *gorm.DB, onlyAmbiguous, onlyChanged bool) ([]models.InventoryClassification, error) {
+	var items []models.InventoryItem
+	if err := db.Select("id", "product_name", "category", "emoji").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	result := []models.InventoryClassification{}
+	for _, item := range items {
+		class := emoji.Classify(item.ProductName)
+		row := models.InventoryClassification{
+			ID:                item.ID,
+			ProductName:       item.ProductName,
+			Emoji:             item.Emoji,
+			SuggestedCategory: class.Category,
+			SuggestedEmoji:    class.Emoji,
+			Confidence:        class.Confidence,
+			Keyword:           class.Keyword,
+			Ambiguous:         class.Ambiguous,
+			Candidates:        []string{},
+		}
+		if item.Category != nil {
+			row.Category = *item.Category
+		}
+		for _, candidate := range class.Candidates {
+			row.Candidates = append(row.Candidates, candidate.Category)
+		}
+		row.Changed = row.Category != row.SuggestedCategory
+		if (onlyAmbiguous && !row.Ambiguous) || (onlyChanged && !row.Changed) {
+			continue
+		}
+		result = append(result, row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence < result[j].Confidence
+		}
+		return result[i].ProductName < result[j].ProductName
+	})
+	return result, nil
+}
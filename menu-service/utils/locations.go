@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"monolith/menu-service/models"
+)
+
+// 🏠 Найти точку по ID или коду (?location=center)
+func ResolveLocation(db *gorm.DB, ref string) (*models.Location, error) {
+	var location models.Location
+	query := db.Where("code = ?", ref)
+	if _, err := uuid.Parse(ref); err == nil {
+		query = db.Where("id = ?", ref)
+	}
+	if err := query.First(&location).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// 💲 Цены и скрытые блюда точки. Для пустого ID точки — базовое меню без изменений
+type LocationMenu struct {
+	overrides map[string]models.LocationMenuItem
+}
+
+func LoadLocationMenu(db *gorm.DB, locationID string) (*LocationMenu, error) {
+	m := &LocationMenu{overrides: map[string]models.LocationMenuItem{}}
+	if locationID == "" {
+		return m, nil
+	}
+	var rows []models.LocationMenuItem
+	if err := db.Where("location_id = ?", locationID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		m.overrides[row.MenuItemID] = row
+	}
+	return m, nil
+}
+
+// Не продаётся ли блюдо в точке
+func (m *LocationMenu) Hidden(menuItemID string) bool {
+	return m.overrides[menuItemID].Hidden
+}
+
+// Цена блюда в точке
+func (m *LocationMenu) Price(menuItemID string, base models.Money) models.Money {
+	if o, ok := m.overrides[menuItemID]; ok && o.Price != nil {
+		return *o.Price
+	}
+	return base
+}
+
+// Хватает ли продукта на порцию на складе точки; без записи об остатке — общий склад
+func productInStockAt(product models.InventoryItem, stock map[string]int, ing models.CalculationIngredient) bool {
+	if grams, ok := stock[product.ID]; ok {
+		return product.Available && grams >= ing.AmountGrams
+	}
+	return productInStock(product, ing)
+}
+
+// ⛔ Блюда, снятые с продажи в точке: ручной режим стоп-листа общий для сети,
+// автоматический считается по остаткам точки. Пустой ID — общий стоп-лист.
+func StoppedMenuItemIDsAt(db *gorm.DB, locationID string) (map[string]bool, error) {
+	if locationID == "" {
+		return StoppedMenuItemIDs(db)
+	}
+
+	var entries []models.StopListEntry
+	if err := db.Where("manual_override <> ''").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	manual := make(map[string]string, len(entries))
+	for _, e := range entries {
+		manual[e.MenuItemID] = e.ManualOverride
+	}
+
+	var ids []string
+	if err := db.Model(&models.Calculation{}).Distinct().Pluck("menu_item_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	calcs, err := GetActiveCalculations(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return nil, err
+	}
+	var stocks []models.LocationStock
+	if err := db.Where("location_id = ?", locationID).Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	stock := make(map[string]int, len(stocks))
+	for _, s := range stocks {
+		stock[s.InventoryItemID] = s.WeightGrams
+	}
+
+	result := map[string]bool{}
+	for id, override := range manual {
+		if override == models.StopOverrideStop {
+			result[id] = true
+		}
+	}
+	inStock := func(product models.InventoryItem, ing models.CalculationIngredient) bool {
+		return productInStockAt(product, stock, ing)
+	}
+	for id, calc := range calcs {
+		if manual[id] == "" && len(missingProducts(calc, products, inStock)) > 0 {
+			result[id] = true
+		}
+	}
+	return result, nil
+}
+
+// Роль пользователя в точке ("" — не работает в ней)
+func StaffRole(db *gorm.DB, userID, locationID string) (string, error) {
+	var a models.StaffAssignment
+	err := db.Where("user_id = ? AND location_id = ?", userID, locationID).Limit(1).Find(&a).Error
+	return a.Role, err
+}
+
+// 📊 Продажи по точкам за период и итог по сети. Отменённые заказы не учитываются.
+func LocationSalesReport(db *gorm.DB, from, to time.Time) (*models.LocationSalesReport, error) {
+	type orderRow struct {
+		LocationID *string
+		Orders     int64
+		Revenue    models.Money
+	}
+	var orders []orderRow
+	if err := db.Model(&models.Order{}).
+		Select("location_id, COUNT(*) AS orders, COALESCE(SUM(total_price), 0) AS revenue").
+		Where("created_at >= ? AND created_at < ? AND status <> ?", from, to, models.OrderCancelled).
+		Group("location_id").
+		Scan(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	type itemsRow struct {
+		LocationID *string
+		Items      int64
+	}
+	var items []itemsRow
+	if err := db.Table("order_items").
+		Select("orders.location_id, COALESCE(SUM(order_items.quantity), 0) AS items").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.deleted_at IS NULL AND order_items.deleted_at IS NULL").
+		Where("orders.created_at >= ? AND orders.created_at < ? AND orders.status <> ?", from, to, models.OrderCancelled).
+		Group("orders.location_id").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	itemsByLocation := map[string]int64{}
+	for _, row := range items {
+		itemsByLocation[locationKey(row.LocationID)] = row.Items
+	}
+
+	var locations []models.Location
+	if err := db.Order("name").Find(&locations).Error; err != nil {
+		return nil, err
+	}
+	names := map[string]string{"": "Без точки"}
+	for _, l := range locations {
+		names[l.ID] = l.Name
+	}
+
+	report := &models.LocationSalesReport{
+		From:      from,
+		To:        to,
+		Locations: []models.LocationSales{},
+		Total:     models.LocationSales{LocationName: "Вся сеть"},
+	}
+	byLocation := map[string]*models.LocationSales{}
+	for _, l := range locations {
+		report.Locations = append(report.Locations, models.LocationSales{LocationID: l.ID, LocationName: l.Name})
+	}
+	for i := range report.Locations {
+		byLocation[report.Locations[i].LocationID] = &report.Locations[i]
+	}
+	for _, row := range orders {
+		key := locationKey(row.LocationID)
+		sales, ok := byLocation[key]
+		if !ok {
+			report.Locations = append(report.Locations, models.LocationSales{LocationID: key, LocationName: names[key]})
+			sales = &report.Locations[len(report.Locations)-1]
+			byLocation = reindexSales(report.Locations)
+		}
+		sales.Orders = row.Orders
+		sales.Revenue = row.Revenue
+		sales.ItemsSold = itemsByLocation[key]
+	}
+	for i := range report.Locations {
+		s := &report.Locations[i]
+		if s.Orders > 0 {
+			s.AverageCheck = s.Revenue.Div(s.Orders)
+		}
+		report.Total.Orders += s.Orders
+		report.Total.Revenue += s.Revenue
+		report.Total.ItemsSold += s.ItemsSold
+	}
+	if report.Total.Orders > 0 {
+		report.Total.AverageCheck = report.Total.Revenue.Div(report.Total.Orders)
+	}
+	return report, nil
+}
+
+func locationKey(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return *id
+}
+
+// После append адреса элементов среза могут смениться — индекс строим заново
+func reindexSales(list []models.LocationSales) map[string]*models.LocationSales {
+	index := make(map[string]*models.LocationSales, len(list))
+	for i := range list {
+		index[list[i].LocationID] = &list[i]
+	}
+	return index
+}
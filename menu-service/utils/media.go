@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"monolith/menu-service/media"
+	"monolith/menu-service/models"
+)
+
+// Записать ссылку и варианты изображения в блюдо, категорию или отзыв
+func setOwnerImage(tx *gorm.DB, ownerType, ownerID, imageURL string, variants []models.MediaVariant) error {
+	var model interface{} = &models.MenuItem{ImageURL: imageURL, ImageVariants: variants}
+	switch ownerType {
+	case models.MediaOwnerCategory:
+		model = &models.Category{ImageURL: imageURL, ImageVariants: variants}
+	case models.MediaOwnerReview:
+		model = &models.Review{ImageURL: imageURL, ImageVariants: variants}
+	}
+	return tx.Model(model).Where("id = ?", ownerID).Select("image_url", "image_variants").Updates(model).Error
+}
+
+// 🖼 Загрузить новое изображение владельца и заменить им старое.
+// Файлы старого изображения удаляются только после успешной записи в БД.
+func ReplaceOwnerImage(ctx context.Context, db *gorm.DB, storage media.Storage, ownerType, ownerID string, data []byte) (string, []models.MediaVariant, error) {
+	asset := models.MediaAsset{
+		ID:        uuid.NewString(),
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+	}
+	prefix := fmt.Sprintf("%s/%s/%s", ownerType, ownerID, asset.ID)
+	variants, keys, err := media.ProcessUpload(ctx, storage, prefix, data)
+	if err != nil {
+		return "", nil, err
+	}
+	asset.Keys = keys
+	imageURL := variants[len(variants)-1].URL
+
+	var old []models.MediaAsset
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&old).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&asset).Error; err != nil {
+			return err
+		}
+		if err := setOwnerImage(tx, ownerType, ownerID, imageURL, variants); err != nil {
+			return err
+		}
+		if len(old) > 0 {
+			return tx.Where("owner_type = ? AND owner_id = ? AND id <> ?", ownerType, ownerID, asset.ID).
+				Delete(&models.MediaAsset{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		media.Cleanup(ctx, storage, keys)
+		return "", nil, err
+	}
+
+	removeAssetFiles(ctx, storage, old)
+	return imageURL, variants, nil
+}
+
+// 🧹 Удалить все изображения владельца: файлы и записи. Ссылки в самой сущности очищаются,
+// если она ещё существует.
+func RemoveOwnerImages(ctx context.Context, db *gorm.DB, storage media.Storage, ownerType, ownerID string) error {
+	var assets []models.MediaAsset
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&assets).Error; err != nil {
+			return err
+		}
+		if len(assets) == 0 {
+			return nil
+		}
+		if err := setOwnerImage(tx, ownerType, ownerID, "", nil); err != nil {
+			return err
+		}
+		return tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(&models.MediaAsset{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	removeAssetFiles(ctx, storage, assets)
+	return nil
+}
+
+// Удаление файлов не откатывает БД: неудачу только логируем
+func removeAssetFiles(ctx context.Context, storage media.Storage, assets []models.MediaAsset) {
+	for _, a := range assets {
+		if err := media.Cleanup(ctx, storage, a.Keys); err != nil {
+			log.Printf("⚠️ Не удалось удалить файлы изображения %s: %v", a.ID, err)
+		}
+	}
+}
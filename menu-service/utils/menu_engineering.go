@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Блюдо считается популярным, если его доля продаж не ниже 70% от равной доли (правило Kasavana–Smith)
+const popularityFactor = 0.7
+
+var engineeringClassOrder = map[string]int{
+	models.EngineeringStar:      0,
+	models.EngineeringPlowhorse: 1,
+	models.EngineeringPuzzle:    2,
+	models.EngineeringDog:       3,
+}
+
+var engineeringRecommendations = map[string]string{
+	models.EngineeringStar:      "сохранить: держать качество и заметное место в меню",
+	models.EngineeringPlowhorse: "пересмотреть цену или себестоимость порции",
+	models.EngineeringPuzzle:    "продвигать: поднять в меню, переименовать, предлагать официантам",
+	models.EngineeringDog:       "кандидат на удаление или замену",
+}
+
+// 📊 Меню-инжиниринг за период [from, to): популярность и маржинальный доход блюд по матрице Kasavana–Smith.
+// categoryID — категория вместе с подкатегориями, locationID — продажи одной точки (пусто — вся сеть).
+// В анализ попадают опубликованные блюда и блюда, которые продавались в периоде; блюда из комбо
+// учитываются по своей доле цены набора. Себестоимость проданной порции берётся из версии техкарты
+// на момент заказа, а без техкарты — текущая.
+func MenuEngineering(db *gorm.DB, from, to time.Time, categoryID, locationID string) (*models.MenuEngineeringReport, error) {
+	var dishes []models.MenuItemWithCategory
+	if err := dishesWithCategory(db).Scan(&dishes).Error; err != nil {
+		return nil, err
+	}
+	if categoryID != "" {
+		if err := db.Select("id").First(&models.Category{}, "id = ?", categoryID).Error; err != nil {
+			return nil, err
+		}
+		graph, err := loadCategoryGraph(db)
+		if err != nil {
+			return nil, err
+		}
+		inCategory := dishes[:0]
+		for _, dish := range dishes {
+			if graph.within(dish.CategoryID, categoryID) {
+				inCategory = append(inCategory, dish)
+			}
+		}
+		dishes = inCategory
+	}
+
+	type salesRow struct {
+		MenuItemID string
+		Sold       int64
+		Revenue    models.Money
+		FoodCost   models.Money
+	}
+	var rows []salesRow
+	query := db.Table("order_items").
+		Select(`order_items.menu_item_id,
+			SUM(order_items.quantity) AS sold,
+			SUM(order_items.quantity * order_items.price) AS revenue,
+			SUM(order_items.quantity * COALESCE(menu_calculations.total_cost, menu_items.cost_price, 0)) AS food_cost`).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("LEFT JOIN menu_calculations ON menu_calculations.id = order_items.calculation_id").
+		Joins("LEFT JOIN menu_items ON menu_items.id::text = order_items.menu_item_id").
+		Where("orders.deleted_at IS NULL AND order_items.deleted_at IS NULL").
+		Where("orders.created_at >= ? AND orders.created_at < ? AND orders.status <> ?", from, to, models.OrderCancelled)
+	if locationID != "" {
+		query = query.Where("orders.location_id = ?", locationID)
+	}
+	if err := query.Group("order_items.menu_item_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	sales := make(map[string]salesRow, len(rows))
+	for _, row := range rows {
+		sales[row.MenuItemID] = row
+	}
+
+	report := &models.MenuEngineeringReport{
+		From:       from,
+		To:         to,
+		CategoryID: categoryID,
+		LocationID: locationID,
+		Items:      []models.MenuEngineeringItem{},
+		Summary:    models.MenuEngineeringSummary{Classes: map[string]int{}},
+	}
+	summary := &report.Summary
+	for _, dish := range dishes {
+		sold := sales[dish.ID]
+		if !dish.Published && sold.Sold == 0 {
+			continue
+		}
+		item := models.MenuEngineeringItem{
+			MenuItemID:    dish.ID,
+			Name:          dish.Name,
+			CategoryID:    dish.CategoryID,
+			CategoryName:  dish.CategoryName,
+			Sold:          sold.Sold,
+			Price:         dish.Price,
+			FoodCost:      dish.CostPrice,
+			Revenue:       sold.Revenue,
+			TotalFoodCost: sold.FoodCost,
+			TotalMargin:   sold.Revenue - sold.FoodCost,
+		}
+		if sold.Sold > 0 {
+			item.Price = sold.Revenue.Div(sold.Sold)
+			item.FoodCost = sold.FoodCost.Div(sold.Sold)
+		}
+		item.Margin = item.Price - item.FoodCost
+		item.FoodCostPercent = foodCostPercent(item.FoodCost, item.Price)
+		report.Items = append(report.Items, item)
+
+		summary.Sold += item.Sold
+		summary.Revenue += item.Revenue
+		summary.FoodCost += item.TotalFoodCost
+	}
+
+	summary.Dishes = len(report.Items)
+	summary.Margin = summary.Revenue - summary.FoodCost
+	summary.FoodCostPercent = foodCostPercent(summary.FoodCost, summary.Revenue)
+	if summary.Dishes == 0 {
+		return report, nil
+	}
+	summary.PopularityThreshold = round1(popularityFactor * 100 / float64(summary.Dishes))
+	if summary.Sold > 0 {
+		summary.AverageMargin = summary.Margin.Div(summary.Sold)
+	} else {
+		// Продаж нет — порог выгодности считается по меню, а не по продажам
+		var total models.Money
+		for _, item := range report.Items {
+			total += item.Margin
+		}
+		summary.AverageMargin = total.Div(int64(summary.Dishes))
+	}
+
+	popularityThreshold := popularityFactor / float64(summary.Dishes)
+	for i := range report.Items {
+		item := &report.Items[i]
+		mix := 0.0
+		if summary.Sold > 0 {
+			mix = float64(item.Sold) / float64(summary.Sold)
+		}
+		item.MenuMixPercent = round1(mix * 100)
+		popular := summary.Sold > 0 && mix >= popularityThreshold
+		profitable := item.Margin >= summary.AverageMargin
+		item.Popularity, item.Profitability = highLow(popular), highLow(profitable)
+		switch {
+		case popular && profitable:
+			item.Class = models.EngineeringStar
+		case popular:
+			item.Class = models.EngineeringPlowhorse
+		case profitable:
+			item.Class = models.EngineeringPuzzle
+		default:
+			item.Class = models.EngineeringDog
+		}
+		item.Recommendation = engineeringRecommendations[item.Class]
+		summary.Classes[item.Class]++
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Class != b.Class {
+			return engineeringClassOrder[a.Class] < engineeringClassOrder[b.Class]
+		}
+		if a.TotalMargin != b.TotalMargin {
+			return a.TotalMargin > b.TotalMargin
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// Фудкост в процентах от цены
+func foodCostPercent(cost, price models.Money) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return round1(float64(cost) / float64(price) * 100)
+}
+
+func highLow(high bool) string {
+	if high {
+		return "high"
+	}
+	return "low"
+}
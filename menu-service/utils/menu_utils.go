@@ -77,39 +77,12 @@ func DeleteMenuItem(db *gorm.DB, id string) error {
 	return db.Delete(&models.MenuItem{}, "id = ?", id).Error
 }
 
-// Сохранить или обновить калькуляцию
-func SaveDishCalculation(db *gorm.DB, calc *models.Calculation) error {
-	var existing models.Calculation
-	if err := db.Where("menu_item_id = ?", calc.MenuItemID).First(&existing).Error; err == nil {
-		calc.ID = existing.ID
-		calc.CreatedAt = time.Now()
-		if err := db.Model(&existing).Updates(calc).Error; err != nil {
-			return err
-		}
-		_ = db.Where("calculation_id = ?", existing.ID).Delete(&models.CalculationIngredient{})
-	} else {
-		calc.CreatedAt = time.Now()
-		if err := db.Create(calc).Error; err != nil {
-			return err
-		}
-	}
-
-	for _, ing := range calc.Ingredients {
-		ing.CalculationID = calc.ID
-		ing.CreatedAt = time.Now()
-		if err := db.Create(&ing).Error; err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// Получить калькуляцию по блюду
+// Получить действующую калькуляцию по блюду
 func GetCalculationByMenuItemID(db *gorm.DB, menuItemID string) (*models.Calculation, error) {
 	var calc models.Calculation
 	if err := db.
-		Where("menu_item_id = ?", menuItemID).
-		Order("created_at DESC").
+		Where("menu_item_id = ? AND effective_from <= ?", menuItemID, time.Now()).
+		Order("effective_from DESC, version DESC").
 		First(&calc).Error; err != nil {
 		return nil, err
 	}
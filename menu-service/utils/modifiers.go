@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Ошибка проверки выбора — текст отдаётся клиенту как есть
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Рассчитанная на сервере позиция корзины
+type PricedCartLine struct {
+	MenuItem     models.MenuItem
+	Modifiers    []models.SelectedModifier
+	ModifiersKey string
+	UnitPrice    models.Money
+}
+
+// Получить группы модификаторов блюда вместе с опциями
+func GetModifierGroups(db *gorm.DB, menuItemID string) ([]models.ModifierGroup, error) {
+	groups := []models.ModifierGroup{}
+	err := db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, created_at")
+		}).
+		Where("menu_item_id = ?", menuItemID).
+		Order("sort_order, created_at").
+		Find(&groups).Error
+	return groups, err
+}
+
+// Проверить настройки группы перед сохранением
+func ValidateModifierGroup(group *models.ModifierGroup) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return validationErrorf("название группы модификаторов обязательно")
+	}
+	if len(group.Options) == 0 {
+		return validationErrorf("группа %q должна содержать хотя бы одну опцию", group.Name)
+	}
+	if group.Required && group.MinSelect < 1 {
+		group.MinSelect = 1
+	}
+	if group.MinSelect < 0 || group.MaxSelect < 0 {
+		return validationErrorf("min_select и max_select не могут быть отрицательными")
+	}
+	if group.MaxSelect > 0 && group.MinSelect > group.MaxSelect {
+		return validationErrorf("min_select не может быть больше max_select")
+	}
+	if group.MinSelect > len(group.Options) {
+		return validationErrorf("min_select больше количества опций в группе %q", group.Name)
+	}
+	for _, opt := range group.Options {
+		if strings.TrimSpace(opt.Name) == "" {
+			return validationErrorf("название опции обязательно")
+		}
+	}
+	return nil
+}
+
+// Проверить выбранные модификаторы и посчитать цену позиции на сервере.
+// Если в группе ничего не выбрано, подставляются опции по умолчанию.
+// Для точки (locationID не пуст) действуют её цена и доступность блюда.
+func PriceCartLine(db *gorm.DB, locationID, menuItemID string, optionIDs []string) (*PricedCartLine, error) {
+	var item models.MenuItem
+	if err := db.First(&item, "id = ?", menuItemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, validationErrorf("блюдо не найдено")
+		}
+		return nil, err
+	}
+	reasons, err := UnavailableReasonsAt(db, locationID, []string{item.ID})
+	if err != nil {
+		return nil, err
+	}
+	locationMenu, err := LoadLocationMenu(db, locationID)
+	if err != nil {
+		return nil, err
+	}
+	item.Price = locationMenu.Price(item.ID, item.Price)
+	if reason, ok := reasons[item.ID]; ok {
+		return nil, validationErrorf("блюдо %q сейчас недоступно для заказа: %s", item.Name, reason)
+	}
+
+	groups, err := GetModifierGroups(db, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if selected[id] {
+			return nil, validationErrorf("опция %s выбрана несколько раз", id)
+		}
+		selected[id] = true
+	}
+
+	line := &PricedCartLine{MenuItem: item, UnitPrice: item.Price, Modifiers: []models.SelectedModifier{}}
+	matched := 0
+	for _, group := range groups {
+		var picked []models.ModifierOption
+		for _, opt := range group.Options {
+			if selected[opt.ID] {
+				if !opt.Available {
+					return nil, validationErrorf("опция %q сейчас недоступна", opt.Name)
+				}
+				picked = append(picked, opt)
+			}
+		}
+		matched += len(picked)
+
+		if len(picked) == 0 {
+			for _, opt := range group.Options {
+				if opt.IsDefault && opt.Available {
+					picked = append(picked, opt)
+				}
+			}
+		}
+
+		min := group.MinSelect
+		if group.Required && min < 1 {
+			min = 1
+		}
+		if len(picked) < min {
+			return nil, validationErrorf("в группе %q нужно выбрать минимум %d", group.Name, min)
+		}
+		if group.MaxSelect > 0 && len(picked) > group.MaxSelect {
+			return nil, validationErrorf("в группе %q можно выбрать максимум %d", group.Name, group.MaxSelect)
+		}
+
+		for _, opt := range picked {
+			line.UnitPrice += opt.PriceDelta
+			line.Modifiers = append(line.Modifiers, models.SelectedModifier{
+				GroupID:    group.ID,
+				GroupName:  group.Name,
+				OptionID:   opt.ID,
+				OptionName: opt.Name,
+				PriceDelta: opt.PriceDelta,
+			})
+		}
+	}
+	if matched != len(selected) {
+		return nil, validationErrorf("выбраны опции, которые не относятся к блюду %q", item.Name)
+	}
+
+	ids := make([]string, 0, len(line.Modifiers))
+	for _, m := range line.Modifiers {
+		ids = append(ids, m.OptionID)
+	}
+	sort.Strings(ids)
+	line.ModifiersKey = strings.Join(ids, ",")
+
+	return line, nil
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Фильтр по диете для публичного меню
+type DietaryFilter struct {
+	ExcludeAllergens []string
+	Vegetarian       bool
+	Vegan            bool
+	MaxKcal          float64 // 0 — без ограничения
+}
+
+// Задан ли хотя бы один фильтр
+func (f DietaryFilter) Active() bool {
+	return len(f.ExcludeAllergens) > 0 || f.Vegetarian || f.Vegan || f.MaxKcal > 0
+}
+
+// Подходит ли блюдо под фильтр. Блюда без полной калькуляции или с продуктами,
+// не проверенными на аллергены, не проходят: для аллергиков неизвестный состав опаснее пустой выдачи.
+func (f DietaryFilter) Match(n *models.DishNutrition) bool {
+	if !f.Active() {
+		return true
+	}
+	if n == nil || !n.Complete {
+		return false
+	}
+	for _, excluded := range f.ExcludeAllergens {
+		for _, a := range n.Allergens {
+			if a == excluded {
+				return false
+			}
+		}
+	}
+	if f.Vegetarian && !n.Vegetarian {
+		return false
+	}
+	if f.Vegan && !n.Vegan {
+		return false
+	}
+	if f.MaxKcal > 0 && n.PerPortion.Kcal > f.MaxKcal {
+		return false
+	}
+	return true
+}
+
+// Вес нетто ингредиента с учётом отходов
+func NetGrams(amountGrams int, wastePercent float64) float64 {
+	return float64(amountGrams) * (1 - wastePercent/100)
+}
+
+// Рассчитать КБЖУ на порцию и на 100 г, аллергены и диетические признаки блюд.
+// Берётся действующая версия техкарты; она рассчитана на одну порцию выходом TotalOutputGrams.
+func CalculateNutrition(db *gorm.DB, menuItemIDs []string) (map[string]*models.DishNutrition, error) {
+	calcs, err := GetActiveCalculations(db, menuItemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*models.DishNutrition, len(calcs))
+	for menuItemID, calc := range calcs {
+		result[menuItemID] = dishNutrition(calc, products)
+	}
+	return result, nil
+}
+
+func dishNutrition(calc models.Calculation, products productIndex) *models.DishNutrition {
+	n := &models.DishNutrition{
+		OutputGrams: calc.TotalOutputGrams,
+		Allergens:   []string{},
+		Vegetarian:  len(calc.Ingredients) > 0,
+		Vegan:       len(calc.Ingredients) > 0,
+		Complete:    len(calc.Ingredients) > 0,
+	}
+
+	allergens := map[string]bool{}
+	for _, ing := range calc.Ingredients {
+		product, ok := products.find(ing)
+		if !ok {
+			n.Complete = false
+			n.Vegetarian = false
+			n.Vegan = false
+			n.Missing = append(n.Missing, ing.ProductName)
+			continue
+		}
+
+		factor := NetGrams(ing.AmountGrams, ing.WastePercent) / 100
+		n.PerPortion.Kcal += product.KcalPer100g * factor
+		n.PerPortion.Protein += product.ProteinPer100g * factor
+		n.PerPortion.Fat += product.FatPer100g * factor
+		n.PerPortion.Carbs += product.CarbsPer100g * factor
+
+		// Аллергены не указаны — это не «без аллергенов»: состав блюда считается неизвестным
+		if product.Allergens == nil {
+			n.Complete = false
+			n.Unchecked = append(n.Unchecked, product.ProductName)
+		}
+		for _, a := range product.Allergens {
+			allergens[a] = true
+		}
+		n.Vegetarian = n.Vegetarian && (product.Vegetarian || product.Vegan)
+		n.Vegan = n.Vegan && product.Vegan
+	}
+
+	for a := range allergens {
+		n.Allergens = append(n.Allergens, a)
+	}
+	sort.Strings(n.Allergens)
+
+	if calc.TotalOutputGrams > 0 {
+		scale := 100 / float64(calc.TotalOutputGrams)
+		n.Per100g = models.NutritionFacts{
+			Kcal:    n.PerPortion.Kcal * scale,
+			Protein: n.PerPortion.Protein * scale,
+			Fat:     n.PerPortion.Fat * scale,
+			Carbs:   n.PerPortion.Carbs * scale,
+		}
+	}
+	n.PerPortion = roundFacts(n.PerPortion)
+	n.Per100g = roundFacts(n.Per100g)
+	return n
+}
+
+func roundFacts(f models.NutritionFacts) models.NutritionFacts {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return models.NutritionFacts{
+		Kcal:    round(f.Kcal),
+		Protein: round(f.Protein),
+		Fat:     round(f.Fat),
+		Carbs:   round(f.Carbs),
+	}
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 📈 Записать изменение цены или себестоимости блюда.
+// Заполняются старые и новые значения; если ничего не изменилось, запись не создаётся
+// (кроме создания блюда — первая запись задаёт начальную точку истории).
+func RecordPriceChange(tx *gorm.DB, change models.PriceChange) error {
+	if change.Source != models.PriceSourceCreate &&
+		change.Price == change.OldPrice && change.CostPrice == change.OldCostPrice {
+		return nil
+	}
+	change.ID = ""
+	change.Margin = change.Price - change.CostPrice
+	return tx.Create(&change).Error
+}
+
+// Запись истории по состоянию блюда до и после изменения
+func priceChangeOf(before models.MenuItem, price, costPrice models.Money, source, authorID string) models.PriceChange {
+	return models.PriceChange{
+		MenuItemID:   before.ID,
+		OldPrice:     before.Price,
+		Price:        price,
+		OldCostPrice: before.CostPrice,
+		CostPrice:    costPrice,
+		Source:       source,
+		AuthorID:     authorID,
+	}
+}
+
+// 📈 Записать изменение блюда, сохранённого поверх состояния before
+func RecordMenuItemPriceChange(tx *gorm.DB, before, after models.MenuItem, source, authorID string) error {
+	return RecordPriceChange(tx, priceChangeOf(before, after.Price, after.CostPrice, source, authorID))
+}
+
+// 📈 История цены и себестоимости блюда за период (from/to — nil без ограничения)
+// вместе с ожидающими плановыми изменениями
+func GetPriceHistory(db *gorm.DB, menuItemID string, from, to *time.Time) (*models.PriceHistory, error) {
+	var item models.MenuItem
+	if err := db.Select("id", "name", "price", "cost_price", "margin").First(&item, "id = ?", menuItemID).Error; err != nil {
+		return nil, err
+	}
+
+	history := &models.PriceHistory{
+		MenuItemID: item.ID,
+		Name:       item.Name,
+		Price:      item.Price,
+		CostPrice:  item.CostPrice,
+		Margin:     item.Margin,
+		Changes:    []models.PriceChange{},
+		Scheduled:  []models.ScheduledPriceChange{},
+	}
+	query := db.Where("menu_item_id = ?", menuItemID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	if err := query.Order("created_at, id").Find(&history.Changes).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("menu_item_id = ? AND status = ?", menuItemID, models.ScheduledPricePending).
+		Order("effective_at, created_at").
+		Find(&history.Scheduled).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ⏰ Запланировать новую цену блюда на будущую дату
+func SchedulePriceChange(db *gorm.DB, menuItemID string, price models.Money, effectiveAt time.Time, authorID, comment string) (*models.ScheduledPriceChange, error) {
+	if price < 0 {
+		return nil, validationErrorf("цена не может быть отрицательной")
+	}
+	if effectiveAt.IsZero() {
+		return nil, validationErrorf("укажите effective_at — дату вступления цены в силу")
+	}
+	if !effectiveAt.After(time.Now()) {
+		return nil, validationErrorf("дата вступления в силу должна быть в будущем")
+	}
+
+	var item models.MenuItem
+	if err := db.Select("id").First(&item, "id = ?", menuItemID).Error; err != nil {
+		return nil, err
+	}
+	scheduled := &models.ScheduledPriceChange{
+		MenuItemID:  item.ID,
+		Price:       price,
+		EffectiveAt: effectiveAt,
+		Status:      models.ScheduledPricePending,
+		AuthorID:    authorID,
+		Comment:     strings.TrimSpace(comment),
+	}
+	if err := db.Create(scheduled).Error; err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// ❌ Отменить ещё не применённое плановое изменение цены
+func CancelScheduledPriceChange(db *gorm.DB, scheduleID, userID string) (*models.ScheduledPriceChange, error) {
+	var scheduled models.ScheduledPriceChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&scheduled, "id = ?", scheduleID).Error; err != nil {
+			return err
+		}
+		if scheduled.Status != models.ScheduledPricePending {
+			return validationErrorf("плановое изменение уже %s", scheduledStatusText(scheduled.Status))
+		}
+		scheduled.Status = models.ScheduledPriceCancelled
+		scheduled.CancelledBy = userID
+		return tx.Save(&scheduled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func scheduledStatusText(status string) string {
+	if status == models.ScheduledPriceApplied {
+		return "применено"
+	}
+	return "отменено"
+}
+
+// ⏰ Применить плановые изменения цен, дата которых наступила.
+// Изменения одного блюда применяются по порядку дат, поэтому в силе остаётся последнее.
+func ApplyScheduledPrices(db *gorm.DB) error {
+	var due []models.ScheduledPriceChange
+	if err := db.Where("status = ? AND effective_at <= ?", models.ScheduledPricePending, time.Now()).
+		Order("effective_at, created_at").
+		Find(&due).Error; err != nil {
+		return err
+	}
+	for _, scheduled := range due {
+		if err := applyScheduledPrice(db, scheduled.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyScheduledPrice(db *gorm.DB, scheduleID string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var scheduled models.ScheduledPriceChange
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&scheduled, "id = ?", scheduleID).Error; err != nil {
+			return err
+		}
+		// Могли отменить или применить параллельно
+		if scheduled.Status != models.ScheduledPricePending {
+			return nil
+		}
+		now := time.Now()
+		var item models.MenuItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", scheduled.MenuItemID).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&models.MenuItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+				"price":  scheduled.Price,
+				"margin": scheduled.Price - item.CostPrice,
+			}).Error; err != nil {
+				return err
+			}
+			change := priceChangeOf(item, scheduled.Price, item.CostPrice, models.PriceSourceScheduled, scheduled.AuthorID)
+			change.ScheduleID = &scheduled.ID
+			change.Comment = scheduled.Comment
+			if err := RecordPriceChange(tx, change); err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Блюдо удалено — изменение применять некуда
+			return tx.Model(&scheduled).Updates(map[string]interface{}{"status": models.ScheduledPriceCancelled}).Error
+		default:
+			return err
+		}
+		return tx.Model(&scheduled).Updates(map[string]interface{}{
+			"status":     models.ScheduledPriceApplied,
+			"applied_at": now,
+		}).Error
+	})
+}
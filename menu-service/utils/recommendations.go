@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Настройки майнинга: окно истории заказов и минимальное число совместных заказов для правила.
+// Задаются в Init из RECOMMENDATIONS_WINDOW_DAYS и RECOMMENDATIONS_MIN_PAIR_ORDERS.
+var (
+	RecommendationWindowDays = 180
+	RecommendationMinPairs   = 3
+)
+
+// 🤝 Пересчитать правила «заказывают вместе» по истории заказов.
+// Учитываются неотменённые заказы за окно RECOMMENDATIONS_WINDOW_DAYS; блюда одного комбо
+// между собой не связываются — их состав задан меню, а не выбором гостя.
+func RecomputeAssociations(db *gorm.DB) error {
+	since := time.Now().AddDate(0, 0, -RecommendationWindowDays)
+	var totalOrders int64
+	if err := db.Model(&models.Order{}).
+		Where("status <> ? AND created_at >= ?", models.OrderCancelled, since).
+		Count(&totalOrders).Error; err != nil {
+		return err
+	}
+
+	type itemCount struct {
+		MenuItemID string
+		Orders     int64
+	}
+	var itemCounts []itemCount
+	if err := db.Table("order_items").
+		Select("order_items.menu_item_id, COUNT(DISTINCT order_items.order_id) AS orders").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.deleted_at IS NULL AND orders.deleted_at IS NULL").
+		Where("orders.status <> ? AND orders.created_at >= ?", models.OrderCancelled, since).
+		Group("order_items.menu_item_id").
+		Scan(&itemCounts).Error; err != nil {
+		return err
+	}
+	ordersWith := make(map[string]int64, len(itemCounts))
+	for _, ic := range itemCounts {
+		ordersWith[ic.MenuItemID] = ic.Orders
+	}
+
+	type pairCount struct {
+		MenuItemID string
+		RelatedID  string
+		Orders     int64
+	}
+	var pairs []pairCount
+	if err := db.Table("order_items AS a").
+		Select("a.menu_item_id, b.menu_item_id AS related_id, COUNT(DISTINCT a.order_id) AS orders").
+		Joins("JOIN order_items AS b ON b.order_id = a.order_id AND b.menu_item_id <> a.menu_item_id AND b.deleted_at IS NULL").
+		Joins("JOIN orders ON orders.id = a.order_id").
+		Where("a.deleted_at IS NULL AND orders.deleted_at IS NULL").
+		Where("orders.status <> ? AND orders.created_at >= ?", models.OrderCancelled, since).
+		Where("NOT (COALESCE(a.combo_line, '') <> '' AND a.combo_line = b.combo_line)").
+		Group("a.menu_item_id, b.menu_item_id").
+		Having("COUNT(DISTINCT a.order_id) >= ?", RecommendationMinPairs).
+		Scan(&pairs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rules := make([]models.MenuItemAssociation, 0, len(pairs))
+	for _, p := range pairs {
+		antecedent, consequent := ordersWith[p.MenuItemID], ordersWith[p.RelatedID]
+		if totalOrders == 0 || antecedent == 0 || consequent == 0 {
+			continue
+		}
+		support := float64(p.Orders) / float64(totalOrders)
+		confidence := float64(p.Orders) / float64(antecedent)
+		rules = append(rules, models.MenuItemAssociation{
+			MenuItemID: p.MenuItemID,
+			RelatedID:  p.RelatedID,
+			PairOrders: int(p.Orders),
+			Support:    support,
+			Confidence: confidence,
+			Lift:       confidence / (float64(consequent) / float64(totalOrders)),
+			ComputedAt: now,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.MenuItemAssociation{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rules, 500).Error
+	})
+}
+
+// 💡 Рекомендации к набору блюд (одно блюдо или содержимое корзины).
+// Берутся правила с lift > 1; если к кандидату ведут несколько правил, учитывается самое уверенное.
+// Блюда, которые сейчас нельзя заказать в точке (не опубликованы, в стоп-листе, вне расписания), отбрасываются.
+func RecommendMenuItems(db *gorm.DB, locationID string, sourceIDs []string, limit int) ([]models.MenuRecommendation, error) {
+	result := []models.MenuRecommendation{}
+	if len(sourceIDs) == 0 || limit <= 0 {
+		return result, nil
+	}
+
+	var rules []models.MenuItemAssociation
+	if err := db.Where("menu_item_id IN ? AND related_id NOT IN ? AND lift > 1", sourceIDs, sourceIDs).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	best := map[string]models.MenuItemAssociation{}
+	for _, rule := range rules {
+		if current, ok := best[rule.RelatedID]; !ok || betterRule(rule, current) {
+			best[rule.RelatedID] = rule
+		}
+	}
+	if len(best) == 0 {
+		return result, nil
+	}
+
+	candidates := make([]string, 0, len(best))
+	for id := range best {
+		candidates = append(candidates, id)
+	}
+	reasons, err := UnavailableReasonsAt(db, locationID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	orderable := candidates[:0]
+	for _, id := range candidates {
+		if _, blocked := reasons[id]; !blocked {
+			orderable = append(orderable, id)
+		}
+	}
+	sort.Slice(orderable, func(i, j int) bool {
+		a, b := best[orderable[i]], best[orderable[j]]
+		if a.Confidence != b.Confidence || a.Lift != b.Lift {
+			return betterRule(a, b)
+		}
+		return orderable[i] < orderable[j]
+	})
+	if len(orderable) > limit {
+		orderable = orderable[:limit]
+	}
+	if len(orderable) == 0 {
+		return result, nil
+	}
+
+	var items []models.MenuItem
+	if err := db.Where("id IN ?", orderable).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.MenuItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	locationMenu, err := LoadLocationMenu(db, locationID)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := RatingSummaries(db, orderable)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range orderable {
+		item, ok := byID[id]
+		if !ok {
+			continue
+		}
+		item.Price = locationMenu.Price(item.ID, item.Price)
+		item.Rating, item.RatingCount = ratings[id].Average, ratings[id].Count
+		rule := best[id]
+		result = append(result, models.MenuRecommendation{
+			MenuItem:   item,
+			Support:    rule.Support,
+			Confidence: rule.Confidence,
+			Lift:       rule.Lift,
+		})
+	}
+	return result, nil
+}
+
+// Правило a сильнее b: выше уверенность, при равенстве — lift
+func betterRule(a, b models.MenuItemAssociation) bool {
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	return a.Lift > b.Lift
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+const maxReviewTextLength = 2000
+
+// Отзыв может оставить только покупатель с доставленным заказом этого блюда
+var ErrNotVerifiedBuyer = errors.New("отзыв можно оставить только после доставки заказа с этим блюдом")
+
+// Доставленный заказ пользователя с блюдом (в том числе в составе комбо).
+// orderID = 0 — последний такой заказ.
+func findDeliveredOrder(db *gorm.DB, userID, menuItemID string, orderID uint) (uint, error) {
+	query := db.Model(&models.Order{}).
+		Select("orders.id").
+		Joins("JOIN order_items ON order_items.order_id = orders.id AND order_items.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.menu_item_id = ?",
+			userID, models.OrderDelivered, menuItemID).
+		Order("orders.created_at DESC").
+		Limit(1)
+	if orderID != 0 {
+		query = query.Where("orders.id = ?", orderID)
+	}
+	var ids []uint
+	if err := query.Pluck("orders.id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, ErrNotVerifiedBuyer
+	}
+	return ids[0], nil
+}
+
+// ⭐ Оставить или изменить отзыв о блюде. Один отзыв на блюдо от пользователя;
+// изменённый отзыв снова уходит на модерацию.
+func SubmitReview(db *gorm.DB, userID, menuItemID string, rating int, text string, orderID uint) (*models.Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, validationErrorf("оценка должна быть от 1 до 5")
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxReviewTextLength {
+		return nil, validationErrorf("текст отзыва не должен превышать %d символов", maxReviewTextLength)
+	}
+
+	verifiedOrder, err := findDeliveredOrder(db, userID, menuItemID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var review models.Review
+	err = db.Where("user_id = ? AND menu_item_id = ?", userID, menuItemID).Limit(1).Find(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	review.UserID = userID
+	review.MenuItemID = menuItemID
+	review.OrderID = verifiedOrder
+	review.Rating = rating
+	review.Text = text
+	review.Status = models.ReviewPending
+	review.ModeratedBy = ""
+	review.ModeratedAt = nil
+	review.ModerationNote = ""
+	if err := db.Save(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// 🛡 Одобрить или отклонить отзыв
+func ModerateReview(db *gorm.DB, reviewID, status, moderatorID, note string) (*models.Review, error) {
+	if status != models.ReviewApproved && status != models.ReviewRejected {
+		return nil, validationErrorf("статус модерации должен быть approved или rejected")
+	}
+	var review models.Review
+	if err := db.First(&review, "id = ?", reviewID).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	review.Status = status
+	review.ModeratedBy = moderatorID
+	review.ModeratedAt = &now
+	review.ModerationNote = strings.TrimSpace(note)
+	if err := db.Save(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ⭐ Средняя оценка и число одобренных отзывов по блюдам
+func RatingSummaries(db *gorm.DB, menuItemIDs []string) (map[string]models.RatingSummary, error) {
+	result := map[string]models.RatingSummary{}
+	if len(menuItemIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		MenuItemID string
+		Average    float64
+		Count      int
+	}
+	if err := db.Model(&models.Review{}).
+		Select("menu_item_id, AVG(rating) AS average, COUNT(*) AS count").
+		Where("status = ? AND menu_item_id IN ?", models.ReviewApproved, menuItemIDs).
+		Group("menu_item_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.MenuItemID] = models.RatingSummary{Average: round1(row.Average), Count: row.Count}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Порог похожести для нечёткого поиска (pg_trgm.word_similarity_threshold для оператора <%)
+const searchSimilarityThreshold = 0.3
+
+// Текст, экранированный для HTML: название и описание вводит администратор, а подсветку
+// <mark> клиент выводит как HTML — поэтому экранируется исходный текст, а не результат
+func htmlEscapeSQL(expr string) string {
+	return `replace(replace(replace(replace(replace(` + expr +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// Параметры поиска по меню
+type MenuSearchParams struct {
+	Query              string
+	Limit              int
+	Offset             int
+	IncludeUnpublished bool
+}
+
+// Поиск блюд: полнотекстовый (russian) + триграммы для опечаток.
+// Ищет по названию, описанию и названию категории, подсвечивает совпадения.
+func SearchMenuItems(db *gorm.DB, params MenuSearchParams) ([]models.MenuSearchResult, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return []models.MenuSearchResult{}, nil
+	}
+
+	published := "menu_items.published = TRUE"
+	if params.IncludeUnpublished {
+		published = "TRUE"
+	}
+
+	var results []models.MenuSearchResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Оператор <% использует триграммные индексы по lower(name), а порог берёт из настройки;
+		// set_config(..., true) действует только в этой транзакции
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			strconv.FormatFloat(searchSimilarityThreshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+		return tx.Raw(`
+			WITH q AS (
+				SELECT websearch_to_tsquery('russian', @query) AS tsq, lower(@query) AS raw
+			)
+			SELECT
+				menu_items.*,
+				categories.name AS category_name,
+				ts_rank_cd(menu_items.search_vector, q.tsq) AS rank,
+				GREATEST(
+					word_similarity(q.raw, lower(menu_items.name)),
+					word_similarity(q.raw, lower(coalesce(categories.name, '')))
+				) AS similarity,
+				ts_headline('russian', `+htmlEscapeSQL("menu_items.name")+`, q.tsq,
+					'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name_highlight,
+				ts_headline('russian', `+htmlEscapeSQL("coalesce(menu_items.description, '')")+`, q.tsq,
+					'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS description_highlight
+			FROM menu_items
+			LEFT JOIN categories ON menu_items.category_id = categories.id
+			CROSS JOIN q
+			WHERE `+published+`
+			  AND (
+				menu_items.search_vector @@ q.tsq
+				OR q.raw <% lower(menu_items.name)
+				OR q.raw <% lower(categories.name)
+			  )
+			ORDER BY rank DESC, similarity DESC, menu_items.name
+			LIMIT @limit OFFSET @offset
+		`, map[string]interface{}{
+			"query":  query,
+			"limit":  params.Limit,
+			"offset": params.Offset,
+		}).Scan(&results).Error
+	})
+
+	return results, err
+}
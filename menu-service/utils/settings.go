@@ -0,0 +1,12 @@
+package utils
+
+import "monolith/menu-service/config"
+
+// ⚙️ Прочитать настройки меню из окружения. Вызывается после загрузки .env,
+// поэтому значения не читаются при инициализации пакета.
+func Init() {
+	restaurantLocation = loadRestaurantLocation()
+	SupportedLanguages = loadSupportedLanguages()
+	RecommendationWindowDays = config.Int("RECOMMENDATIONS_WINDOW_DAYS", 180, 1)
+	RecommendationMinPairs = config.Int("RECOMMENDATIONS_MIN_PAIR_ORDERS", 3, 1)
+}
@@ -0,0 +1,491 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"monolith/menu-service/models"
+)
+
+// Ошибки жизненного цикла черновика — обработчики отвечают на них 409
+var (
+	ErrDraftClosed   = errors.New("черновик уже опубликован или отменён")
+	ErrDraftOutdated = errors.New("после создания черновика меню было опубликовано заново")
+)
+
+// Ключ advisory-блокировки: публикации и откаты меню выполняются строго по очереди
+const menuPublishLock = 7_041_001
+
+// 📋 Текущее живое меню в виде содержимого снимка
+func LiveMenuContent(db *gorm.DB) (models.MenuContent, error) {
+	var categories []models.Category
+	if err := db.Order("sort_order, name").Find(&categories).Error; err != nil {
+		return models.MenuContent{}, err
+	}
+	var items []models.MenuItem
+	if err := db.Order("sort_order, name").Find(&items).Error; err != nil {
+		return models.MenuContent{}, err
+	}
+
+	content := models.MenuContent{
+		Categories: make([]models.SnapshotCategory, 0, len(categories)),
+		Items:      make([]models.SnapshotItem, 0, len(items)),
+	}
+	for _, c := range categories {
+		content.Categories = append(content.Categories, models.SnapshotCategory{
+			ID:          c.ID,
+			Name:        c.Name,
+			ParentID:    c.ParentID,
+			SortOrder:   c.SortOrder,
+			Description: c.Description,
+			Hidden:      c.Hidden,
+		})
+	}
+	for _, item := range items {
+		content.Items = append(content.Items, models.SnapshotItem{
+			ID:          item.ID,
+			Name:        item.Name,
+			Description: item.Description,
+			CategoryID:  item.CategoryID,
+			Price:       item.Price,
+			Currency:    item.Currency,
+			ImageURL:    item.ImageURL,
+			Published:   item.Published,
+			SortOrder:   item.SortOrder,
+		})
+	}
+	return content, nil
+}
+
+// Номер последнего опубликованного снимка (0 — снимков ещё не было)
+func CurrentSnapshotVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Model(&models.MenuSnapshot{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Список снимков без содержимого, от новых к старым
+func ListSnapshots(db *gorm.DB) ([]models.MenuSnapshot, error) {
+	snapshots := []models.MenuSnapshot{}
+	err := db.Omit("content").Order("version DESC").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// Снимок по номеру версии
+func GetSnapshot(db *gorm.DB, version int) (*models.MenuSnapshot, error) {
+	var snapshot models.MenuSnapshot
+	if err := db.Where("version = ?", version).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ✏️ Новый черновик — копия живого меню
+func CreateDraft(db *gorm.DB, name, authorID string) (*models.MenuDraft, error) {
+	content, err := LiveMenuContent(db)
+	if err != nil {
+		return nil, err
+	}
+	version, err := CurrentSnapshotVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = fmt.Sprintf("Черновик от версии %d", version)
+	}
+	draft := &models.MenuDraft{
+		Name:        name,
+		Status:      models.DraftOpen,
+		BaseVersion: version,
+		AuthorID:    authorID,
+		Content:     content,
+	}
+	if err := db.Create(draft).Error; err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// ✏️ Изменить открытый черновик. edit правит содержимое, затем оно целиком проверяется.
+func EditDraft(db *gorm.DB, draftID string, edit func(content *models.MenuContent) error) (*models.MenuDraft, error) {
+	var draft models.MenuDraft
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&draft, "id = ?", draftID).Error; err != nil {
+			return err
+		}
+		if draft.Status != models.DraftOpen {
+			return ErrDraftClosed
+		}
+		if err := edit(&draft.Content); err != nil {
+			return err
+		}
+		if err := ValidateMenuContent(&draft.Content); err != nil {
+			return err
+		}
+		return tx.Model(&draft).Select("content", "updated_at").Updates(&draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// 🔍 Проверить содержимое меню и дополнить новые записи: ID и валюта по умолчанию
+func ValidateMenuContent(content *models.MenuContent) error {
+	categories := map[string]*models.SnapshotCategory{}
+	names := map[string]bool{}
+	for i := range content.Categories {
+		c := &content.Categories[i]
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			return validationErrorf("у категории %d нет названия", i+1)
+		}
+		if c.ID == "" {
+			c.ID = uuid.NewString()
+		}
+		if categories[c.ID] != nil {
+			return validationErrorf("категория %s указана дважды", c.ID)
+		}
+		if names[strings.ToLower(c.Name)] {
+			return validationErrorf("две категории с названием %q", c.Name)
+		}
+		categories[c.ID] = c
+		names[strings.ToLower(c.Name)] = true
+	}
+	for _, c := range categories {
+		seen := map[string]bool{c.ID: true}
+		for p := c.ParentID; p != nil; p = categories[*p].ParentID {
+			if categories[*p] == nil {
+				return validationErrorf("у категории %q нет родителя %s", c.Name, *p)
+			}
+			if seen[*p] {
+				return validationErrorf("категория %q вложена сама в себя", c.Name)
+			}
+			seen[*p] = true
+		}
+	}
+
+	items := map[string]bool{}
+	for i := range content.Items {
+		item := &content.Items[i]
+		item.Name = strings.TrimSpace(item.Name)
+		if item.Name == "" {
+			return validationErrorf("у блюда %d нет названия", i+1)
+		}
+		if item.ID == "" {
+			item.ID = uuid.NewString()
+		}
+		if items[item.ID] {
+			return validationErrorf("блюдо %s указано дважды", item.ID)
+		}
+		items[item.ID] = true
+		if item.Price < 0 {
+			return validationErrorf("цена блюда %q не может быть отрицательной", item.Name)
+		}
+		if item.Currency == "" {
+			item.Currency = models.DefaultCurrency
+		}
+		if item.CategoryID != "" && categories[item.CategoryID] == nil {
+			return validationErrorf("категория блюда %q не найдена в черновике", item.Name)
+		}
+	}
+	return nil
+}
+
+// 🚀 Опубликовать черновик: живое меню приводится к содержимому черновика
+// и сохраняется новым снимком — всё в одной транзакции.
+// Если после создания черновика меню публиковали, нужен force.
+func PublishDraft(db *gorm.DB, draftID, authorID, comment string, force bool) (*models.MenuSnapshot, error) {
+	var snapshot *models.MenuSnapshot
+	var itemIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", menuPublishLock).Error; err != nil {
+			return err
+		}
+		var draft models.MenuDraft
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&draft, "id = ?", draftID).Error; err != nil {
+			return err
+		}
+		if draft.Status != models.DraftOpen {
+			return ErrDraftClosed
+		}
+		current, err := CurrentSnapshotVersion(tx)
+		if err != nil {
+			return err
+		}
+		if current != draft.BaseVersion && !force {
+			return ErrDraftOutdated
+		}
+		if err := ValidateMenuContent(&draft.Content); err != nil {
+			return err
+		}
+
+		if comment == "" {
+			comment = draft.Name
+		}
+		snapshot = &models.MenuSnapshot{
+			Version:  current + 1,
+			DraftID:  &draft.ID,
+			AuthorID: authorID,
+			Comment:  comment,
+			Content:  draft.Content,
+		}
+		if itemIDs, err = applyMenuContent(tx, draft.Content, authorID); err != nil {
+			return err
+		}
+		if err := tx.Create(snapshot).Error; err != nil {
+			return err
+		}
+		return tx.Model(&draft).Updates(map[string]interface{}{
+			"status":       models.DraftPublished,
+			"published_as": snapshot.Version,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	recalculateAfterPublish(db, itemIDs)
+	return snapshot, nil
+}
+
+// ⏪ Откат: содержимое старого снимка публикуется как новая версия
+func RollbackMenu(db *gorm.DB, version int, authorID string) (*models.MenuSnapshot, error) {
+	var snapshot *models.MenuSnapshot
+	var itemIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", menuPublishLock).Error; err != nil {
+			return err
+		}
+		target, err := GetSnapshot(tx, version)
+		if err != nil {
+			return err
+		}
+		current, err := CurrentSnapshotVersion(tx)
+		if err != nil {
+			return err
+		}
+		snapshot = &models.MenuSnapshot{
+			Version:      current + 1,
+			RollbackFrom: version,
+			AuthorID:     authorID,
+			Comment:      fmt.Sprintf("Откат к версии %d", version),
+			Content:      target.Content,
+		}
+		if itemIDs, err = applyMenuContent(tx, target.Content, authorID); err != nil {
+			return err
+		}
+		return tx.Create(snapshot).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	recalculateAfterPublish(db, itemIDs)
+	return snapshot, nil
+}
+
+// Себестоимость новых и изменённых блюд берётся из техкарт уже после фиксации
+func recalculateAfterPublish(db *gorm.DB, itemIDs []string) {
+	if len(itemIDs) == 0 {
+		return
+	}
+	if _, err := RecalculateDishCosts(db, itemIDs); err != nil {
+		log.Printf("⚠️ Не удалось пересчитать себестоимость после публикации меню: %v", err)
+	}
+}
+
+// Привести живые таблицы к содержимому снимка. Ничего не удаляется:
+// блюда, которых нет в снимке, снимаются с публикации, а категории — скрываются,
+// чтобы старые заказы и корзины сохраняли ссылки.
+func applyMenuContent(tx *gorm.DB, content models.MenuContent, authorID string) ([]string, error) {
+	categoryIDs := make([]string, 0, len(content.Categories))
+	for _, c := range content.Categories {
+		categoryIDs = append(categoryIDs, c.ID)
+		values := map[string]interface{}{
+			"name":        c.Name,
+			"parent_id":   nil, // родители расставляются вторым проходом
+			"sort_order":  c.SortOrder,
+			"description": c.Description,
+			"hidden":      c.Hidden,
+		}
+		res := tx.Model(&models.Category{}).Where("id = ?", c.ID).Updates(values)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			if err := tx.Create(&models.Category{
+				ID:          c.ID,
+				Name:        c.Name,
+				SortOrder:   c.SortOrder,
+				Description: c.Description,
+				Hidden:      c.Hidden,
+			}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, c := range content.Categories {
+		if c.ParentID == nil {
+			continue
+		}
+		if err := tx.Model(&models.Category{}).Where("id = ?", c.ID).Update("parent_id", *c.ParentID).Error; err != nil {
+			return nil, err
+		}
+	}
+	hide := tx.Model(&models.Category{})
+	if len(categoryIDs) > 0 {
+		hide = hide.Where("id NOT IN ?", categoryIDs)
+	} else {
+		hide = hide.Where("TRUE")
+	}
+	if err := hide.Update("hidden", true).Error; err != nil {
+		return nil, err
+	}
+
+	itemIDs := make([]string, 0, len(content.Items))
+	for _, item := range content.Items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	// Цены до публикации — для истории цен
+	var current []models.MenuItem
+	if len(itemIDs) > 0 {
+		if err := tx.Select("id", "price", "cost_price").Where("id IN ?", itemIDs).Find(&current).Error; err != nil {
+			return nil, err
+		}
+	}
+	before := make(map[string]models.MenuItem, len(current))
+	for _, item := range current {
+		before[item.ID] = item
+	}
+
+	for _, item := range content.Items {
+		values := map[string]interface{}{
+			"name":        item.Name,
+			"description": item.Description,
+			"category_id": item.CategoryID,
+			"price":       item.Price,
+			"margin":      gorm.Expr("? - cost_price", item.Price),
+			"currency":    item.Currency,
+			"image_url":   item.ImageURL,
+			"published":   item.Published,
+			"sort_order":  item.SortOrder,
+		}
+		res := tx.Model(&models.MenuItem{}).Where("id = ?", item.ID).Updates(values)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			if err := tx.Create(&models.MenuItem{
+				ID:          item.ID,
+				Name:        item.Name,
+				Description: item.Description,
+				CategoryID:  item.CategoryID,
+				Price:       item.Price,
+				Margin:      item.Price,
+				Currency:    item.Currency,
+				ImageURL:    item.ImageURL,
+				Published:   item.Published,
+				SortOrder:   item.SortOrder,
+			}).Error; err != nil {
+				return nil, err
+			}
+		}
+		old, existed := before[item.ID]
+		source := models.PriceSourceSnapshot
+		if !existed {
+			old = models.MenuItem{ID: item.ID}
+			source = models.PriceSourceCreate
+		}
+		if err := RecordPriceChange(tx, priceChangeOf(old, item.Price, old.CostPrice, source, authorID)); err != nil {
+			return nil, err
+		}
+	}
+	unpublish := tx.Model(&models.MenuItem{})
+	if len(itemIDs) > 0 {
+		unpublish = unpublish.Where("id NOT IN ?", itemIDs)
+	} else {
+		unpublish = unpublish.Where("TRUE")
+	}
+	if err := unpublish.Update("published", false).Error; err != nil {
+		return nil, err
+	}
+	return itemIDs, nil
+}
+
+// 🔀 Разница между двумя версиями содержимого меню
+func DiffMenuContent(from, to models.MenuContent) models.MenuDiff {
+	diff := models.MenuDiff{
+		AddedItems:        []models.SnapshotItem{},
+		RemovedItems:      []models.SnapshotItem{},
+		ChangedItems:      []models.EntityChange{},
+		AddedCategories:   []models.SnapshotCategory{},
+		RemovedCategories: []models.SnapshotCategory{},
+		ChangedCategories: []models.EntityChange{},
+	}
+
+	oldItems := make(map[string]models.SnapshotItem, len(from.Items))
+	for _, item := range from.Items {
+		oldItems[item.ID] = item
+	}
+	for _, item := range to.Items {
+		old, ok := oldItems[item.ID]
+		if !ok {
+			diff.AddedItems = append(diff.AddedItems, item)
+			continue
+		}
+		delete(oldItems, item.ID)
+		if changes := fieldChanges(old, item); len(changes) > 0 {
+			diff.ChangedItems = append(diff.ChangedItems, models.EntityChange{ID: item.ID, Name: item.Name, Changes: changes})
+		}
+	}
+	for _, item := range from.Items {
+		if _, ok := oldItems[item.ID]; ok {
+			diff.RemovedItems = append(diff.RemovedItems, item)
+		}
+	}
+
+	oldCategories := make(map[string]models.SnapshotCategory, len(from.Categories))
+	for _, c := range from.Categories {
+		oldCategories[c.ID] = c
+	}
+	for _, c := range to.Categories {
+		old, ok := oldCategories[c.ID]
+		if !ok {
+			diff.AddedCategories = append(diff.AddedCategories, c)
+			continue
+		}
+		delete(oldCategories, c.ID)
+		if changes := fieldChanges(old, c); len(changes) > 0 {
+			diff.ChangedCategories = append(diff.ChangedCategories, models.EntityChange{ID: c.ID, Name: c.Name, Changes: changes})
+		}
+	}
+	for _, c := range from.Categories {
+		if _, ok := oldCategories[c.ID]; ok {
+			diff.RemovedCategories = append(diff.RemovedCategories, c)
+		}
+	}
+	return diff
+}
+
+// Поля структуры, значения которых различаются; имя поля — его JSON-ключ
+func fieldChanges(before, after interface{}) []models.FieldChange {
+	var changes []models.FieldChange
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+	t := bv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "id" {
+			continue
+		}
+		b, a := bv.Field(i).Interface(), av.Field(i).Interface()
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, models.FieldChange{Field: name, Before: b, After: a})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"time"
+
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Хватает ли продукта на одну порцию
+func productInStock(product models.InventoryItem, ing models.CalculationIngredient) bool {
+	return product.Available && product.WeightGrams >= ing.AmountGrams
+}
+
+// Продукты, которых не хватает на порцию блюда. inStock проверяет остаток на общем складе или в точке.
+// Продукта нет на складе (удалён или не заведён) — блюдо тоже нельзя приготовить.
+func missingProducts(calc models.Calculation, products productIndex,
+	inStock func(models.InventoryItem, models.CalculationIngredient) bool) []string {
+	missing := []string{}
+	for _, ing := range calc.Ingredients {
+		product, ok := products.find(ing)
+		if !ok {
+			missing = append(missing, ing.ProductName)
+		} else if !inStock(product, ing) {
+			missing = append(missing, product.ProductName)
+		}
+	}
+	return missing
+}
+
+// ⛔ Пересчитать автоматический стоп-лист по наличию продуктов.
+// menuItemIDs = nil — пересчитать все блюда с калькуляцией.
+// Ручной режим записей не меняется; пустые записи удаляются.
+func RefreshStopList(db *gorm.DB, menuItemIDs []string) error {
+	if menuItemIDs == nil {
+		if err := db.Model(&models.Calculation{}).Distinct().Pluck("menu_item_id", &menuItemIDs).Error; err != nil {
+			return err
+		}
+	}
+	if len(menuItemIDs) == 0 {
+		return nil
+	}
+
+	calcs, err := GetActiveCalculations(db, menuItemIDs)
+	if err != nil {
+		return err
+	}
+	products, err := loadProductsForCalculations(db, calcs)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range menuItemIDs {
+			missing := missingProducts(calcs[id], products, productInStock)
+
+			entry := models.StopListEntry{
+				MenuItemID:      id,
+				AutoStopped:     len(missing) > 0,
+				MissingProducts: missing,
+				UpdatedAt:       time.Now(),
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "menu_item_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"auto_stopped", "missing_products", "updated_at"}),
+			}).Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		return tx.
+			Where("menu_item_id IN ? AND auto_stopped = FALSE AND manual_override = ''", menuItemIDs).
+			Delete(&models.StopListEntry{}).Error
+	})
+}
+
+// Установить ручной режим стоп-листа для блюда
+func SetStopOverride(db *gorm.DB, menuItemID, override, reason, userID string) (*models.StopListEntry, error) {
+	switch override {
+	case models.StopOverrideNone, models.StopOverrideStop, models.StopOverrideAvailable:
+	default:
+		return nil, validationErrorf("override должен быть stop, available или пустым")
+	}
+
+	entry := models.StopListEntry{
+		MenuItemID:      menuItemID,
+		MissingProducts: []string{},
+		ManualOverride:  override,
+		Reason:          reason,
+		UpdatedBy:       userID,
+		UpdatedAt:       time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "menu_item_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"manual_override", "reason", "updated_by", "updated_at"}),
+	}).Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	if err := RefreshStopList(db, []string{menuItemID}); err != nil {
+		return nil, err
+	}
+
+	var saved models.StopListEntry
+	if err := db.First(&saved, "menu_item_id = ?", menuItemID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &entry, nil
+		}
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// Текущий стоп-лист (только снятые с продажи блюда)
+func GetStopList(db *gorm.DB) ([]models.StopListEntry, error) {
+	var entries []models.StopListEntry
+	if err := db.Order("updated_at DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.MenuItemID)
+	}
+	names := map[string]string{}
+	if len(ids) > 0 {
+		var items []models.MenuItem
+		if err := db.Select("id", "name").Where("id IN ?", ids).Find(&items).Error; err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			names[item.ID] = item.Name
+		}
+	}
+
+	stopped := []models.StopListEntry{}
+	for _, e := range entries {
+		if e.Stopped() {
+			e.Name = names[e.MenuItemID]
+			stopped = append(stopped, e)
+		}
+	}
+	return stopped, nil
+}
+
+// ID блюд, снятых с продажи
+func StoppedMenuItemIDs(db *gorm.DB) (map[string]bool, error) {
+	var entries []models.StopListEntry
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.Stopped() {
+			result[e.MenuItemID] = true
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	"monolith/menu-service/models"
+)
+
+func testProducts(items ...models.InventoryItem) productIndex {
+	index := productIndex{byID: map[string]models.InventoryItem{}, byName: map[string]models.InventoryItem{}}
+	for _, item := range items {
+		index.byID[item.ID] = item
+		index.byName[productKey(item.ProductName)] = item
+	}
+	return index
+}
+
+func TestMissingProducts(t *testing.T) {
+	salmonID, riceID, deletedID := "salmon", "rice", "deleted"
+	products := testProducts(
+		models.InventoryItem{ID: salmonID, ProductName: "Лосось", WeightGrams: 1000, Available: true},
+		models.InventoryItem{ID: riceID, ProductName: "Рис", WeightGrams: 50, Available: true},
+	)
+	calc := func(ingredients ...models.CalculationIngredient) models.Calculation {
+		return models.Calculation{Ingredients: ingredients}
+	}
+	salmon := models.CalculationIngredient{InventoryItemID: &salmonID, ProductName: "Лосось", AmountGrams: 80}
+	rice := models.CalculationIngredient{InventoryItemID: &riceID, ProductName: "Рис", AmountGrams: 100}
+	deleted := models.CalculationIngredient{InventoryItemID: &deletedID, ProductName: "Нори", AmountGrams: 5}
+	byName := models.CalculationIngredient{ProductName: " лосось ", AmountGrams: 80}
+
+	// Остатки точки: лосося мало, риса достаточно; для нори записи нет
+	stock := map[string]int{salmonID: 50, riceID: 500}
+	atLocation := func(product models.InventoryItem, ing models.CalculationIngredient) bool {
+		return productInStockAt(product, stock, ing)
+	}
+
+	tests := []struct {
+		name     string
+		calc     models.Calculation
+		inStock  func(models.InventoryItem, models.CalculationIngredient) bool
+		expected []string
+	}{
+		{name: "общий склад: всё есть", calc: calc(salmon, byName), inStock: productInStock, expected: []string{}},
+		{name: "общий склад: не хватает", calc: calc(salmon, rice), inStock: productInStock, expected: []string{"Рис"}},
+		{name: "общий склад: продукт удалён", calc: calc(salmon, deleted), inStock: productInStock, expected: []string{"Нори"}},
+		{name: "точка: остатки точки", calc: calc(salmon, rice), inStock: atLocation, expected: []string{"Лосось"}},
+		{name: "точка: продукт удалён", calc: calc(rice, deleted), inStock: atLocation, expected: []string{"Нори"}},
+	}
+	for _, tt := range tests {
+		got := missingProducts(tt.calc, products, tt.inStock)
+		if !slices.Equal(got, tt.expected) {
+			t.Errorf("%s: missingProducts = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
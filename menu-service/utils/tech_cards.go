@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"monolith/menu-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Блюда с названием категории, в порядке меню
+func dishesWithCategory(db *gorm.DB) *gorm.DB {
+	return db.Table("menu_items").
+		Select("menu_items.*, categories.name as category_name").
+		Joins("LEFT JOIN categories ON menu_items.category_id = categories.id").
+		Order("categories.sort_order, categories.name, menu_items.sort_order, menu_items.name")
+}
+
+// 📄 Техкарта блюда для печати: указанная версия или действующая (version = 0)
+func TechCardForDish(db *gorm.DB, menuItemID string, version int) (*models.TechCard, error) {
+	var dishes []models.MenuItemWithCategory
+	if err := dishesWithCategory(db).Where("menu_items.id = ?", menuItemID).Scan(&dishes).Error; err != nil {
+		return nil, err
+	}
+	if len(dishes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var calc models.Calculation
+	if version > 0 {
+		found, err := GetCalculationVersion(db, menuItemID, version)
+		if err != nil {
+			return nil, err
+		}
+		calc = *found
+	} else {
+		active, err := GetActiveCalculations(db, []string{menuItemID})
+		if err != nil {
+			return nil, err
+		}
+		found, ok := active[menuItemID]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		calc = found
+	}
+	return &models.TechCard{Dish: dishes[0], Calculation: calc}, nil
+}
+
+// 📚 Действующие техкарты всех блюд категории и её подкатегорий, в порядке меню.
+// Блюда без техкарты пропускаются.
+func TechCardsForCategory(db *gorm.DB, categoryID string) (*models.Category, []models.TechCard, error) {
+	var category models.Category
+	if err := db.First(&category, "id = ?", categoryID).Error; err != nil {
+		return nil, nil, err
+	}
+	graph, err := loadCategoryGraph(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var all []models.MenuItemWithCategory
+	if err := dishesWithCategory(db).Scan(&all).Error; err != nil {
+		return nil, nil, err
+	}
+	var dishes []models.MenuItemWithCategory
+	var ids []string
+	for _, dish := range all {
+		if graph.within(dish.CategoryID, categoryID) {
+			dishes = append(dishes, dish)
+			ids = append(ids, dish.ID)
+		}
+	}
+
+	calcs, err := GetActiveCalculations(db, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	cards := []models.TechCard{}
+	for _, dish := range dishes {
+		if calc, ok := calcs[dish.ID]; ok {
+			cards = append(cards, models.TechCard{Dish: dish, Calculation: calc})
+		}
+	}
+	return &category, cards, nil
+}